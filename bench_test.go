@@ -0,0 +1,62 @@
+package ddb_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/runtakun/dynamodb-marshaler-go"
+)
+
+func benchmarkSample() *sample {
+	ptr := "ptr"
+	return &sample{
+		Str:             "foo",
+		Bool:            true,
+		Blob:            []byte{0x0, 0x1, 0x2, 0x3, 0x4},
+		Int:             1,
+		Int8:            2,
+		Int16:           3,
+		Int32:           4,
+		Int64:           5,
+		Uint:            1,
+		Uint8:           2,
+		Uint16:          3,
+		Uint32:          4,
+		Uint64:          5,
+		Float32:         math.E,
+		Float64:         math.Pi,
+		Arr:             [3]int{1, 2, 3},
+		InterfaceInt:    12345,
+		InterfaceString: "bar",
+		Map:             map[string]interface{}{"map_foo": "map_bar"},
+		Ptr:             &ptr,
+		Slice:           []string{"f", "o", "o"},
+		EmptySlice:      []int{},
+		Child:           &child{Content: "bar_child"},
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	s := benchmarkSample()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := MarshalE(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	item := Marshal(benchmarkSample())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var s sample
+		if err := Unmarshal(item, &s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	ddb "github.com/runtakun/dynamodb-marshaler-go"
+)
+
+// A config holds the options of a conversion.
+type config struct {
+	toDynamo bool // read plain JSON and write DynamoDB JSON
+	lines    bool
+	pretty   bool
+	sets     bool
+}
+
+// convert reads the items of r and writes them to w in the other format.
+func convert(r io.Reader, w io.Writer, cfg config) error {
+	var out [][]byte
+	values, single := 0, false
+
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		var items []map[string]*dynamodb.AttributeValue
+		var err error
+		if cfg.toDynamo {
+			items, single, err = plainItems(raw)
+		} else {
+			items, single, err = dynamoItems(raw)
+		}
+		if err != nil {
+			return err
+		}
+		values++
+
+		for _, item := range items {
+			b, err := encodeItem(item, cfg)
+			if err != nil {
+				return err
+			}
+			if !cfg.lines {
+				out = append(out, b)
+				continue
+			}
+			if _, err := w.Write(append(b, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.lines {
+		return nil
+	}
+
+	var b []byte
+	if values == 1 && single {
+		b = out[0]
+	} else {
+		b = append([]byte{'['}, bytes.Join(out, []byte{','})...)
+		b = append(b, ']')
+	}
+	if cfg.pretty {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, b, "", "  "); err != nil {
+			return err
+		}
+		b = buf.Bytes()
+	}
+	_, err := w.Write(append(b, '\n'))
+	return err
+}
+
+func encodeItem(item map[string]*dynamodb.AttributeValue, cfg config) ([]byte, error) {
+	if !cfg.toDynamo {
+		return ddb.ToJSON(item)
+	}
+	if cfg.sets {
+		for _, av := range item {
+			inferSet(av)
+		}
+	}
+	return ddb.MarshalDynamoJSON(item)
+}
+
+// plainItems returns the items of a plain JSON object or array of objects,
+// and whether raw is a single item.
+func plainItems(raw json.RawMessage) ([]map[string]*dynamodb.AttributeValue, bool, error) {
+	if !isArray(raw) {
+		item, err := ddb.FromJSON(raw)
+		if err != nil {
+			return nil, false, err
+		}
+		return []map[string]*dynamodb.AttributeValue{item}, true, nil
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, false, err
+	}
+	items := make([]map[string]*dynamodb.AttributeValue, len(list))
+	for i, r := range list {
+		item, err := ddb.FromJSON(r)
+		if err != nil {
+			return nil, false, err
+		}
+		items[i] = item
+	}
+	return items, false, nil
+}
+
+// dynamoItems returns the items of a DynamoDB JSON item, array of items or
+// response holding Item or Items, and whether raw is a single item. raw is
+// taken for a response only if it is not a well-formed item, which a
+// response never is: Items is an array, and the members of Item are not
+// attribute value members.
+func dynamoItems(raw json.RawMessage) ([]map[string]*dynamodb.AttributeValue, bool, error) {
+	if isArray(raw) {
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, false, err
+		}
+		var items []map[string]*dynamodb.AttributeValue
+		for _, r := range list {
+			l, _, err := dynamoItems(r)
+			if err != nil {
+				return nil, false, err
+			}
+			items = append(items, l...)
+		}
+		return items, false, nil
+	}
+
+	var item map[string]*dynamodb.AttributeValue
+	err := ddb.UnmarshalDynamoJSON(raw, &item)
+	if err == nil {
+		return []map[string]*dynamodb.AttributeValue{item}, true, nil
+	}
+	if _, ok := err.(*ddb.DynamoJSONError); !ok {
+		return nil, false, err
+	}
+
+	var resp struct {
+		Item  json.RawMessage
+		Items []json.RawMessage
+	}
+	if json.Unmarshal(raw, &resp) != nil {
+		return nil, false, err
+	}
+	switch {
+	case resp.Items != nil:
+		items := make([]map[string]*dynamodb.AttributeValue, len(resp.Items))
+		for i, r := range resp.Items {
+			if err := ddb.UnmarshalDynamoJSON(r, &items[i]); err != nil {
+				return nil, false, err
+			}
+		}
+		return items, false, nil
+	case resp.Item != nil:
+		if err := ddb.UnmarshalDynamoJSON(resp.Item, &item); err != nil {
+			return nil, false, err
+		}
+		return []map[string]*dynamodb.AttributeValue{item}, true, nil
+	}
+	return nil, false, err
+}
+
+func isArray(raw json.RawMessage) bool {
+	b := bytes.TrimSpace(raw)
+	return len(b) != 0 && b[0] == '['
+}
+
+// inferSet turns the lists in av into SS or NS sets where they hold at
+// least one string or number and no two equal members.
+func inferSet(av *dynamodb.AttributeValue) {
+	switch {
+	case av == nil:
+		return
+	case av.M != nil:
+		for _, mv := range av.M {
+			inferSet(mv)
+		}
+		return
+	case len(av.L) == 0:
+		return
+	}
+
+	for _, l := range av.L {
+		inferSet(l)
+	}
+
+	isS, isN := true, true
+	members := make([]*string, len(av.L))
+	seen := make(map[string]bool, len(av.L))
+	for i, l := range av.L {
+		// Arrays holding empty strings are kept as lists.
+		isS = isS && l != nil && l.S != nil && *l.S != ""
+		isN = isN && l != nil && l.N != nil
+		var key string
+		switch {
+		case isS:
+			members[i], key = l.S, *l.S
+		case isN:
+			// Numbers are equal by value, so 1 and 1.0 cannot both be
+			// members.
+			f, _, err := big.ParseFloat(*l.N, 10, 256, big.ToNearestEven)
+			if err != nil {
+				return
+			}
+			members[i], key = l.N, f.Text('g', -1)
+		default:
+			return
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+	}
+
+	av.L = nil
+	if isS {
+		av.SS = members
+	} else {
+		av.NS = members
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// convertString converts in with cfg and returns the output.
+func convertString(in string, cfg config) (string, error) {
+	var buf bytes.Buffer
+	err := convert(strings.NewReader(in), &buf, cfg)
+	return buf.String(), err
+}
+
+var _ = Describe("Convert", func() {
+
+	Context("to plain JSON", func() {
+		It("should convert an item", func() {
+			out, err := convertString(`{"id": {"S": "1"}, "n": {"N": "1.50"}, "tags": {"SS": ["a"]}}`, config{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`{"id":"1","n":1.50,"tags":["a"]}` + "\n"))
+		})
+
+		It("should convert the items of a scan", func() {
+			out, err := convertString(`{
+				"Items": [{"id": {"S": "1"}}, {"id": {"S": "2"}}],
+				"Count": 2,
+				"ScannedCount": 2,
+				"ConsumedCapacity": null
+			}`, config{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`[{"id":"1"},{"id":"2"}]` + "\n"))
+		})
+
+		It("should convert an empty scan", func() {
+			out, err := convertString(`{"Items": [], "Count": 0, "ScannedCount": 0}`, config{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("[]\n"))
+		})
+
+		It("should convert the item of get-item", func() {
+			out, err := convertString(`{"Item": {"id": {"S": "1"}}}`, config{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`{"id":"1"}` + "\n"))
+		})
+
+		It("should take an attribute named Item for an attribute", func() {
+			out, err := convertString(`{"Item": {"S": "1"}}`, config{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`{"Item":"1"}` + "\n"))
+		})
+
+		It("should convert a table export to JSON Lines", func() {
+			out, err := convertString(`{"Item":{"id":{"S":"1"}}}
+{"Item":{"id":{"S":"2"}}}
+`, config{lines: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("{\"id\":\"1\"}\n{\"id\":\"2\"}\n"))
+		})
+
+		It("should write several values as an array", func() {
+			out, err := convertString(`{"id":{"S":"1"}} [{"id":{"S":"2"}}]`, config{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`[{"id":"1"},{"id":"2"}]` + "\n"))
+		})
+
+		It("should indent the output", func() {
+			out, err := convertString(`{"id": {"S": "1"}, "l": {"L": [{"N": "1"}]}}`, config{pretty: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`{
+  "id": "1",
+  "l": [
+    1
+  ]
+}
+`))
+		})
+
+		It("should report malformed items", func() {
+			for _, in := range []string{
+				`{"id": {"X": "1"}}`,
+				`{"Items": [{"id": "1"}]}`,
+				`{"Item": {"id": 1}}`,
+				`[1]`,
+				`{`,
+			} {
+				_, err := convertString(in, config{})
+				Expect(err).To(HaveOccurred(), in)
+			}
+		})
+	})
+
+	Context("to DynamoDB JSON", func() {
+		It("should convert an item", func() {
+			out, err := convertString(`{"id": "1", "n": 12345678901234567890.5, "l": ["a", "b"], "m": {"ok": true}}`, config{toDynamo: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`{"id":{"S":"1"},"l":{"L":[{"S":"a"},{"S":"b"}]},"m":{"M":{"ok":{"BOOL":true}}},"n":{"N":"12345678901234567890.5"}}` + "\n"))
+		})
+
+		It("should convert JSON Lines", func() {
+			out, err := convertString("{\"id\": \"1\"}\n{\"id\": \"2\"}\n", config{toDynamo: true, lines: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("{\"id\":{\"S\":\"1\"}}\n{\"id\":{\"S\":\"2\"}}\n"))
+		})
+
+		It("should convert an array of items", func() {
+			out, err := convertString(`[{"id": "1"}]`, config{toDynamo: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`[{"id":{"S":"1"}}]` + "\n"))
+		})
+
+		It("should infer sets", func() {
+			out, err := convertString(`{
+				"ss": ["a", "b"],
+				"ns": [1, 2.5],
+				"dup": ["a", "a"],
+				"dupn": [1, 1.0],
+				"blank": ["a", ""],
+				"mixed": ["a", 1],
+				"empty": [],
+				"nested": {"l": [["x"], ["y"]]}
+			}`, config{toDynamo: true, sets: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(MatchJSON(`{
+				"ss": {"SS": ["a", "b"]},
+				"ns": {"NS": ["1", "2.5"]},
+				"dup": {"L": [{"S": "a"}, {"S": "a"}]},
+				"dupn": {"L": [{"N": "1"}, {"N": "1.0"}]},
+				"blank": {"L": [{"S": "a"}, {"S": ""}]},
+				"mixed": {"L": [{"S": "a"}, {"N": "1"}]},
+				"empty": {"L": []},
+				"nested": {"M": {"l": {"L": [{"SS": ["x"]}, {"SS": ["y"]}]}}}
+			}`))
+		})
+
+		It("should reject values other than objects", func() {
+			for _, in := range []string{`1`, `["a"]`, `{"a": 1`} {
+				_, err := convertString(in, config{toDynamo: true})
+				Expect(err).To(HaveOccurred(), in)
+			}
+		})
+	})
+
+	It("should round trip", func() {
+		const in = `{"b":{"B":"aGk="},"bs":{"BS":["YQ=="]},"e":{"S":""},"l":{"L":[{"NULL":true}]},"n":{"N":"1e400"},"ns":{"NS":["1","2"]},"ss":{"SS":["a"]}}` + "\n"
+		plain, err := convertString(in, config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		out, err := convertString(plain, config{toDynamo: true, sets: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(`{"b":{"S":"aGk="},"bs":{"SS":["YQ=="]},"e":{"S":""},"l":{"L":[{"NULL":true}]},"n":{"N":"1e400"},"ns":{"NS":["1","2"]},"ss":{"SS":["a"]}}` + "\n"))
+	})
+})
@@ -0,0 +1,73 @@
+// Command ddbconv converts items read from standard input between plain JSON
+// and the DynamoDB JSON format used by the AWS CLI, table exports and stream
+// records, writing them to standard output.
+//
+//	aws dynamodb scan --table-name users | ddbconv -lines
+//	ddbconv -to dynamodb -sets < users.json
+//
+// The input is a sequence of JSON values: single items, arrays of items, or
+// items one per line as in JSON Lines. DynamoDB JSON may also be the output
+// of get-item, query or scan, whose Item or Items are converted, and lines
+// of a table export such as {"Item":{...}}.
+//
+// Plain JSON is converted with ddb.FromJSON and ddb.ToJSON: numbers keep
+// their text, sets are written as arrays and binaries as base64 strings.
+// With -sets, arrays of distinct strings or of distinct numbers are written
+// as SS and NS sets instead of lists.
+//
+// A single item is written as an object, several items as an array, or one
+// item per line with -lines.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+)
+
+var (
+	to     = flag.String("to", "plain", `output format: "plain" JSON or "dynamodb" JSON; the input is the other one`)
+	lines  = flag.Bool("lines", false, "write one item per line (JSON Lines)")
+	pretty = flag.Bool("pretty", false, "indent the output")
+	sets   = flag.Bool("sets", false, "write arrays of distinct strings or numbers as SS and NS sets; only with -to dynamodb")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: ddbconv [flags] < input\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	cfg := config{
+		lines:  *lines,
+		pretty: *pretty,
+		sets:   *sets,
+	}
+	switch *to {
+	case "plain":
+	case "dynamodb":
+		cfg.toDynamo = true
+	default:
+		fmt.Fprintf(os.Stderr, "ddbconv: unknown format %q\n", *to)
+		usage()
+		os.Exit(2)
+	}
+	if flag.NArg() != 0 || (cfg.lines && cfg.pretty) || (cfg.sets && !cfg.toDynamo) {
+		usage()
+		os.Exit(2)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	err := convert(os.Stdin, w, cfg)
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ddbconv:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,55 @@
+// Package example holds types whose methods are generated by ddbgen. The
+// generated test checks them against the reflection-based functions of
+// package ddb.
+package example
+
+import "time"
+
+//go:generate go run .. -test
+
+// Status is the state of an order.
+type Status string
+
+// Base holds the keys shared by all entities.
+type Base struct {
+	PK        string    `dynamodb:"pk"`
+	SK        string    `dynamodb:"sk"`
+	CreatedAt time.Time `dynamodb:"created_at"`
+}
+
+// Address is a postal address.
+//
+//ddb:generate
+type Address struct {
+	Street string  `dynamodb:"street"`
+	City   string  `dynamodb:"city,omitempty"`
+	Zip    *string `dynamodb:"zip"`
+}
+
+// Order is an order of a customer.
+//
+//ddb:generate
+type Order struct {
+	Base
+	ID         int64                  `dynamodb:"id"`
+	Status     Status                 `dynamodb:"status"`
+	Total      float64                `dynamodb:"total"`
+	Quantity   uint16                 `dynamodb:"quantity,omitempty"`
+	Paid       bool                   `dynamodb:"paid"`
+	Rate       *float32               `dynamodb:"rate"`
+	Note       *string                `dynamodb:"note,omitempty"`
+	Receipt    []byte                 `dynamodb:"receipt"`
+	Tags       []string               `dynamodb:"tags,stringset"`
+	Scores     []int                  `dynamodb:"scores,numberset,omitempty"`
+	Lines      []string               `dynamodb:"lines"`
+	Attributes map[string]interface{} `dynamodb:"attributes"`
+	Shipping   Address                `dynamodb:"shipping"`
+	Billing    *Address               `dynamodb:"billing,omitempty"`
+	ExpiresAt  time.Time              `dynamodb:"expires_at,unixtime,omitempty"`
+	UpdatedAt  *time.Time             `dynamodb:"updated_at,unixmilli"`
+	Timeout    time.Duration          `dynamodb:"timeout"`
+	Extra      interface{}            `dynamodb:"extra"`
+	Ignored    string                 `dynamodb:"-"`
+
+	version int
+}
@@ -0,0 +1,96 @@
+package example
+
+import (
+	"testing"
+	"time"
+
+	ddb "github.com/runtakun/dynamodb-marshaler-go"
+)
+
+func benchmarkOrder() *Order {
+	rate := float32(0.08)
+	note := "leave at the door"
+	zip := "100-0001"
+	updated := time.Unix(1500000000, 0)
+	return &Order{
+		Base: Base{
+			PK:        "customer#1",
+			SK:        "order#1",
+			CreatedAt: time.Unix(1500000000, 0),
+		},
+		ID:         1,
+		Status:     "paid",
+		Total:      1234.5,
+		Quantity:   3,
+		Paid:       true,
+		Rate:       &rate,
+		Note:       &note,
+		Receipt:    []byte{0x0, 0x1, 0x2, 0x3},
+		Tags:       []string{"gift", "express"},
+		Scores:     []int{1, 2, 3},
+		Lines:      []string{"a", "b", "c"},
+		Attributes: map[string]interface{}{"color": "red", "size": 10.0},
+		Shipping:   Address{Street: "1-1 Chiyoda", City: "Tokyo", Zip: &zip},
+		Billing:    &Address{Street: "2-2 Minato", City: "Tokyo"},
+		ExpiresAt:  time.Unix(1600000000, 0),
+		UpdatedAt:  &updated,
+		Timeout:    30 * time.Second,
+		Extra:      "extra",
+	}
+}
+
+func BenchmarkMarshalE(b *testing.B) {
+	v := benchmarkOrder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ddb.MarshalE(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalDynamoDB(b *testing.B) {
+	v := benchmarkOrder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := v.MarshalDynamoDB(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	item, err := ddb.MarshalE(benchmarkOrder())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v Order
+		if err := ddb.Unmarshal(item, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalDynamoDB(b *testing.B) {
+	item, err := ddb.MarshalE(benchmarkOrder())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v Order
+		if err := v.UnmarshalDynamoDB(item); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
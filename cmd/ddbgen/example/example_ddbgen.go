@@ -0,0 +1,371 @@
+// Code generated by ddbgen; DO NOT EDIT.
+
+package example
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	ddb "github.com/runtakun/dynamodb-marshaler-go"
+)
+
+// MarshalDynamoDB converts v to a dynamodb item like ddb.MarshalE.
+func (v *Address) MarshalDynamoDB() (map[string]*dynamodb.AttributeValue, error) {
+	item := make(map[string]*dynamodb.AttributeValue, 3)
+	if v.Street == "" {
+		item["street"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	} else {
+		item["street"] = &dynamodb.AttributeValue{S: aws.String(v.Street)}
+	}
+	if v.City != "" {
+		item["city"] = &dynamodb.AttributeValue{S: aws.String(v.City)}
+	}
+	if v.Zip == nil {
+		item["zip"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	} else {
+		if *v.Zip == "" {
+			item["zip"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+		} else {
+			item["zip"] = &dynamodb.AttributeValue{S: aws.String(*v.Zip)}
+		}
+	}
+	return item, nil
+}
+
+// UnmarshalDynamoDB stores the attributes of item in v like ddb.Unmarshal.
+func (v *Address) UnmarshalDynamoDB(item map[string]*dynamodb.AttributeValue) error {
+	if av, ok := item["street"]; ok {
+		if av.S != nil {
+			v.Street = *av.S
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Street); err != nil {
+			return ddb.WithPath(err, "street")
+		}
+	}
+	if av, ok := item["city"]; ok {
+		if av.S != nil {
+			v.City = *av.S
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.City); err != nil {
+			return ddb.WithPath(err, "city")
+		}
+	}
+	if av, ok := item["zip"]; ok {
+		if av.S != nil {
+			if v.Zip == nil {
+				v.Zip = new(string)
+			}
+			*v.Zip = *av.S
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Zip); err != nil {
+			return ddb.WithPath(err, "zip")
+		}
+	}
+	return nil
+}
+
+// MarshalDynamoDB converts v to a dynamodb item like ddb.MarshalE.
+func (v *Order) MarshalDynamoDB() (map[string]*dynamodb.AttributeValue, error) {
+	item := make(map[string]*dynamodb.AttributeValue, 21)
+	if v.Base.PK == "" {
+		item["pk"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	} else {
+		item["pk"] = &dynamodb.AttributeValue{S: aws.String(v.Base.PK)}
+	}
+	if v.Base.SK == "" {
+		item["sk"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	} else {
+		item["sk"] = &dynamodb.AttributeValue{S: aws.String(v.Base.SK)}
+	}
+	item["created_at"] = &dynamodb.AttributeValue{S: aws.String(v.Base.CreatedAt.Format(time.RFC3339Nano))}
+	item["id"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(v.ID, 10))}
+	if v.Status == "" {
+		item["status"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	} else {
+		item["status"] = &dynamodb.AttributeValue{S: aws.String(string(v.Status))}
+	}
+	item["total"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatFloat(v.Total, 'f', -1, 64))}
+	if v.Quantity != 0 {
+		item["quantity"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatUint(uint64(v.Quantity), 10))}
+	}
+	item["paid"] = &dynamodb.AttributeValue{BOOL: aws.Bool(v.Paid)}
+	if v.Rate == nil {
+		item["rate"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	} else {
+		item["rate"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatFloat(float64(*v.Rate), 'f', -1, 64))}
+	}
+	if v.Note != nil {
+		if *v.Note == "" {
+			item["note"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+		} else {
+			item["note"] = &dynamodb.AttributeValue{S: aws.String(*v.Note)}
+		}
+	}
+	if v.Receipt == nil {
+		item["receipt"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	} else {
+		item["receipt"] = &dynamodb.AttributeValue{B: v.Receipt}
+	}
+	if len(v.Tags) == 0 {
+		item["tags"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	} else {
+		set := make([]*string, 0, len(v.Tags))
+		seen := make(map[string]bool, len(v.Tags))
+		for _, e := range v.Tags {
+			s := e
+			if !seen[s] {
+				seen[s] = true
+				set = append(set, aws.String(s))
+			}
+		}
+		item["tags"] = &dynamodb.AttributeValue{SS: set}
+	}
+	if len(v.Scores) != 0 {
+		set := make([]*string, 0, len(v.Scores))
+		seen := make(map[string]bool, len(v.Scores))
+		for _, e := range v.Scores {
+			s := strconv.FormatInt(int64(e), 10)
+			if !seen[s] {
+				seen[s] = true
+				set = append(set, aws.String(s))
+			}
+		}
+		item["scores"] = &dynamodb.AttributeValue{NS: set}
+	}
+	{
+		av, err := ddb.MarshalAttributeValue(&v.Lines)
+		if err != nil {
+			return nil, ddb.WithPath(err, "lines")
+		}
+		item["lines"] = av
+	}
+	{
+		av, err := ddb.MarshalAttributeValue(&v.Attributes)
+		if err != nil {
+			return nil, ddb.WithPath(err, "attributes")
+		}
+		item["attributes"] = av
+	}
+	{
+		m, err := v.Shipping.MarshalDynamoDB()
+		if err != nil {
+			return nil, ddb.WithPath(err, "shipping")
+		}
+		item["shipping"] = &dynamodb.AttributeValue{M: m}
+	}
+	if v.Billing != nil {
+		{
+			m, err := v.Billing.MarshalDynamoDB()
+			if err != nil {
+				return nil, ddb.WithPath(err, "billing")
+			}
+			item["billing"] = &dynamodb.AttributeValue{M: m}
+		}
+	}
+	if !v.ExpiresAt.IsZero() {
+		item["expires_at"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(v.ExpiresAt.Unix(), 10))}
+	}
+	if v.UpdatedAt == nil {
+		item["updated_at"] = &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+	} else {
+		item["updated_at"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(v.UpdatedAt.Unix()*1000+int64(v.UpdatedAt.Nanosecond())/int64(time.Millisecond), 10))}
+	}
+	item["timeout"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(int64(v.Timeout), 10))}
+	{
+		av, err := ddb.MarshalAttributeValue(&v.Extra)
+		if err != nil {
+			return nil, ddb.WithPath(err, "extra")
+		}
+		item["extra"] = av
+	}
+	return item, nil
+}
+
+// UnmarshalDynamoDB stores the attributes of item in v like ddb.Unmarshal.
+func (v *Order) UnmarshalDynamoDB(item map[string]*dynamodb.AttributeValue) error {
+	if av, ok := item["pk"]; ok {
+		if av.S != nil {
+			v.Base.PK = *av.S
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Base.PK); err != nil {
+			return ddb.WithPath(err, "pk")
+		}
+	}
+	if av, ok := item["sk"]; ok {
+		if av.S != nil {
+			v.Base.SK = *av.S
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Base.SK); err != nil {
+			return ddb.WithPath(err, "sk")
+		}
+	}
+	if av, ok := item["created_at"]; ok {
+		if t, err := time.Parse(time.RFC3339Nano, aws.StringValue(av.S)); av.S != nil && err == nil {
+			v.Base.CreatedAt = t
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Base.CreatedAt); err != nil {
+			return ddb.WithPath(err, "created_at")
+		}
+	}
+	if av, ok := item["id"]; ok {
+		if n, err := strconv.ParseInt(aws.StringValue(av.N), 10, 64); av.N != nil && err == nil {
+			v.ID = n
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.ID); err != nil {
+			return ddb.WithPath(err, "id")
+		}
+	}
+	if av, ok := item["status"]; ok {
+		if av.S != nil {
+			v.Status = Status(*av.S)
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Status); err != nil {
+			return ddb.WithPath(err, "status")
+		}
+	}
+	if av, ok := item["total"]; ok {
+		if n, err := strconv.ParseFloat(aws.StringValue(av.N), 64); av.N != nil && err == nil {
+			v.Total = n
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Total); err != nil {
+			return ddb.WithPath(err, "total")
+		}
+	}
+	if av, ok := item["quantity"]; ok {
+		if n, err := strconv.ParseUint(aws.StringValue(av.N), 10, 16); av.N != nil && err == nil {
+			v.Quantity = uint16(n)
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Quantity); err != nil {
+			return ddb.WithPath(err, "quantity")
+		}
+	}
+	if av, ok := item["paid"]; ok {
+		if av.BOOL != nil {
+			v.Paid = *av.BOOL
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Paid); err != nil {
+			return ddb.WithPath(err, "paid")
+		}
+	}
+	if av, ok := item["rate"]; ok {
+		if n, err := strconv.ParseFloat(aws.StringValue(av.N), 32); av.N != nil && err == nil {
+			if v.Rate == nil {
+				v.Rate = new(float32)
+			}
+			*v.Rate = float32(n)
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Rate); err != nil {
+			return ddb.WithPath(err, "rate")
+		}
+	}
+	if av, ok := item["note"]; ok {
+		if av.S != nil {
+			if v.Note == nil {
+				v.Note = new(string)
+			}
+			*v.Note = *av.S
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Note); err != nil {
+			return ddb.WithPath(err, "note")
+		}
+	}
+	if av, ok := item["receipt"]; ok {
+		if av.B != nil {
+			v.Receipt = av.B
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Receipt); err != nil {
+			return ddb.WithPath(err, "receipt")
+		}
+	}
+	if av, ok := item["tags"]; ok {
+		if err := ddb.UnmarshalAttributeValue(av, &v.Tags); err != nil {
+			return ddb.WithPath(err, "tags")
+		}
+	}
+	if av, ok := item["scores"]; ok {
+		if err := ddb.UnmarshalAttributeValue(av, &v.Scores); err != nil {
+			return ddb.WithPath(err, "scores")
+		}
+	}
+	if av, ok := item["lines"]; ok {
+		if err := ddb.UnmarshalAttributeValue(av, &v.Lines); err != nil {
+			return ddb.WithPath(err, "lines")
+		}
+	}
+	if av, ok := item["attributes"]; ok {
+		if err := ddb.UnmarshalAttributeValue(av, &v.Attributes); err != nil {
+			return ddb.WithPath(err, "attributes")
+		}
+	}
+	if av, ok := item["shipping"]; ok {
+		if av.M != nil {
+			var m Address
+			if err := m.UnmarshalDynamoDB(av.M); err != nil {
+				return ddb.WithPath(err, "shipping")
+			}
+			v.Shipping = m
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Shipping); err != nil {
+			return ddb.WithPath(err, "shipping")
+		}
+	}
+	if av, ok := item["billing"]; ok {
+		if av.M != nil {
+			var m Address
+			if err := m.UnmarshalDynamoDB(av.M); err != nil {
+				return ddb.WithPath(err, "billing")
+			}
+			if v.Billing == nil {
+				v.Billing = new(Address)
+			}
+			*v.Billing = m
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Billing); err != nil {
+			return ddb.WithPath(err, "billing")
+		}
+	}
+	if av, ok := item["expires_at"]; ok {
+		if av.NULL != nil {
+			v.ExpiresAt = time.Time{}
+		} else {
+			if av.S != nil {
+				t, err := time.Parse(time.RFC3339Nano, *av.S)
+				if err != nil {
+					return &ddb.UnmarshalTimeError{Path: "expires_at", Value: *av.S, Err: err}
+				}
+				v.ExpiresAt = t
+			} else if av.N != nil {
+				n, err := strconv.ParseInt(*av.N, 10, 64)
+				if err != nil {
+					if err := ddb.UnmarshalAttributeValue(av, &n); err != nil {
+						return ddb.WithPath(err, "expires_at")
+					}
+				}
+				v.ExpiresAt = time.Unix(n, 0)
+			}
+		}
+	}
+	if av, ok := item["updated_at"]; ok {
+		if av.NULL != nil {
+			v.UpdatedAt = nil
+		} else {
+			if v.UpdatedAt == nil {
+				v.UpdatedAt = new(time.Time)
+			}
+			if av.S != nil {
+				t, err := time.Parse(time.RFC3339Nano, *av.S)
+				if err != nil {
+					return &ddb.UnmarshalTimeError{Path: "updated_at", Value: *av.S, Err: err}
+				}
+				*v.UpdatedAt = t
+			} else if av.N != nil {
+				n, err := strconv.ParseInt(*av.N, 10, 64)
+				if err != nil {
+					if err := ddb.UnmarshalAttributeValue(av, &n); err != nil {
+						return ddb.WithPath(err, "updated_at")
+					}
+				}
+				*v.UpdatedAt = time.Unix(n/1000, n%1000*int64(time.Millisecond))
+			}
+		}
+	}
+	if av, ok := item["timeout"]; ok {
+		if n, err := strconv.ParseInt(aws.StringValue(av.N), 10, 64); av.N != nil && err == nil {
+			v.Timeout = time.Duration(n)
+		} else if err := ddb.UnmarshalAttributeValue(av, &v.Timeout); err != nil {
+			return ddb.WithPath(err, "timeout")
+		}
+	}
+	if av, ok := item["extra"]; ok {
+		if err := ddb.UnmarshalAttributeValue(av, &v.Extra); err != nil {
+			return ddb.WithPath(err, "extra")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package example
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	ddb "github.com/runtakun/dynamodb-marshaler-go"
+)
+
+func TestUnmarshalDynamoDBErrorPath(t *testing.T) {
+	for _, item := range []map[string]*dynamodb.AttributeValue{
+		{"quantity": {N: aws.String("70000")}},
+		{"scores": {NS: []*string{aws.String("1"), aws.String("1.5")}}},
+		{"updated_at": {S: aws.String("yesterday")}},
+	} {
+		var want, got Order
+		wantErr := ddb.Unmarshal(item, &want)
+		gotErr := got.UnmarshalDynamoDB(item)
+		if wantErr == nil || gotErr == nil || gotErr.Error() != wantErr.Error() {
+			t.Errorf("UnmarshalDynamoDB() error = %v, ddb.Unmarshal error = %v", gotErr, wantErr)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A field describes a struct field which is marshaled to an attribute. It
+// mirrors the field type of package ddb, with the Go selector path in place
+// of the reflect index.
+type field struct {
+	name    string
+	tagged  bool
+	index   []int
+	sel     []string // field names leading from the generated type
+	typ     ast.Expr
+	imports map[string]string
+
+	omitEmpty  bool
+	setType    string
+	timeFormat string
+}
+
+// typeFields returns the fields of struct type t which are marshaled to
+// attributes, in index order, following the promotion rules of package ddb.
+func typeFields(pkg *pkgInfo, t *typeDecl, tagNames []string) ([]field, error) {
+	var current []field
+	next := []field{{name: t.name, typ: ast.NewIdent(t.name), imports: t.imports}}
+
+	var count, nextCount map[string]int
+	visited := map[string]bool{}
+
+	var fields []field
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[string]int{}
+
+		for _, f := range current {
+			if visited[f.name] {
+				continue
+			}
+			visited[f.name] = true
+
+			decl := pkg.types[f.name]
+			st := pkg.structType(decl)
+
+			i := -1
+			for _, af := range st.Fields.List {
+				tag := ""
+				if af.Tag != nil {
+					tag, _ = strconv.Unquote(af.Tag.Value)
+				}
+
+				names := af.Names
+				if len(names) == 0 {
+					names = []*ast.Ident{ast.NewIdent(embeddedName(af.Type))}
+				}
+
+				for _, ident := range names {
+					i++
+					anonymous := len(af.Names) == 0
+					ft := pkg.embeddedType(af.Type, decl.imports)
+
+					if anonymous {
+						if !ast.IsExported(ident.Name) && !ft.isStruct {
+							continue
+						}
+					} else if !ast.IsExported(ident.Name) {
+						continue
+					}
+
+					name, opts := parseTag(lookupTag(tag, tagNames))
+					if name == "-" {
+						continue
+					}
+
+					index := append(append([]int(nil), f.index...), i)
+					sel := append(append([]string(nil), f.sel...), ident.Name)
+
+					if name != "" || !anonymous || !ft.isStruct {
+						if anonymous && !ft.known {
+							if name == "" {
+								return nil, fmt.Errorf("%s: cannot promote fields of embedded %s declared in another package", decl.name, ident.Name)
+							}
+						}
+						tagged := name != ""
+						if name == "" {
+							name = ident.Name
+						}
+						fields = append(fields, field{
+							name:       name,
+							tagged:     tagged,
+							index:      index,
+							sel:        sel,
+							typ:        af.Type,
+							imports:    decl.imports,
+							omitEmpty:  opts.omitEmpty(),
+							setType:    opts.setType(),
+							timeFormat: opts.timeFormat(),
+						})
+						if count[f.name] > 1 {
+							fields = append(fields, fields[len(fields)-1])
+						}
+						continue
+					}
+
+					if ft.ptr {
+						return nil, fmt.Errorf("%s: embedded pointer %s is not supported", decl.name, ident.Name)
+					}
+
+					nextCount[ft.name]++
+					if nextCount[ft.name] == 1 {
+						next = append(next, field{name: ft.name, index: index, sel: sel})
+					}
+				}
+			}
+		}
+	}
+
+	sort.Sort(byName(fields))
+
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		name := fields[i].name
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].name != name {
+				break
+			}
+		}
+		if advance == 1 {
+			out = append(out, fields[i])
+			continue
+		}
+		if dominant, ok := dominantField(fields[i : i+advance]); ok {
+			out = append(out, dominant)
+		}
+	}
+
+	fields = out
+	sort.Sort(byIndex(fields))
+
+	return fields, nil
+}
+
+func dominantField(fields []field) (field, bool) {
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tagged == fields[1].tagged {
+		return field{}, false
+	}
+	return fields[0], true
+}
+
+type byName []field
+
+func (x byName) Len() int      { return len(x) }
+func (x byName) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x byName) Less(i, j int) bool {
+	if x[i].name != x[j].name {
+		return x[i].name < x[j].name
+	}
+	if len(x[i].index) != len(x[j].index) {
+		return len(x[i].index) < len(x[j].index)
+	}
+	if x[i].tagged != x[j].tagged {
+		return x[i].tagged
+	}
+	return byIndex(x).Less(i, j)
+}
+
+type byIndex []field
+
+func (x byIndex) Len() int      { return len(x) }
+func (x byIndex) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x byIndex) Less(i, j int) bool {
+	for k, xik := range x[i].index {
+		if k >= len(x[j].index) {
+			return false
+		}
+		if xik != x[j].index[k] {
+			return xik < x[j].index[k]
+		}
+	}
+	return len(x[i].index) < len(x[j].index)
+}
+
+// An embedded describes the type of an embedded field.
+type embedded struct {
+	name     string // type name without package qualifier
+	ptr      bool
+	isStruct bool // a struct type of the package, other than time.Time
+	known    bool // declared in the package or predeclared
+}
+
+func (pkg *pkgInfo) embeddedType(expr ast.Expr, imports map[string]string) embedded {
+	var e embedded
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+		e.ptr = true
+	}
+
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		e.name = expr.Name
+		e.known = true
+		e.isStruct = pkg.structType(pkg.types[expr.Name]) != nil
+	case *ast.SelectorExpr:
+		e.name = expr.Sel.Name
+		if x, ok := expr.X.(*ast.Ident); ok && imports[x.Name] == "time" {
+			e.known = true
+		}
+	}
+
+	return e
+}
+
+// structType returns the struct type t is declared as, following named
+// types of the package, or nil if t is not a struct.
+func (pkg *pkgInfo) structType(t *typeDecl) *ast.StructType {
+	for seen := 0; t != nil && seen < len(pkg.types); seen++ {
+		switch expr := t.expr.(type) {
+		case *ast.StructType:
+			return expr
+		case *ast.Ident:
+			t = pkg.types[expr.Name]
+		case *ast.ParenExpr:
+			t = &typeDecl{name: t.name, expr: expr.X, imports: t.imports}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func embeddedName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	}
+	return ""
+}
+
+// lookupTag returns the value of the first of names present in tag.
+func lookupTag(tag string, names []string) string {
+	for _, name := range names {
+		if v, ok := reflect.StructTag(tag).Lookup(name); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+type tagOptions string
+
+func parseTag(tag string) (string, tagOptions) {
+	if idx := strings.Index(tag, ","); idx != -1 {
+		return tag[:idx], tagOptions(tag[idx+1:])
+	}
+	return tag, ""
+}
+
+func (opts tagOptions) contains(name string) bool {
+	for _, s := range strings.Split(string(opts), ",") {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (opts tagOptions) omitEmpty() bool {
+	return opts.contains("omitempty") || opts.contains("omitifempty")
+}
+
+func (opts tagOptions) setType() string {
+	switch {
+	case opts.contains("stringset"):
+		return "SS"
+	case opts.contains("numberset"):
+		return "NS"
+	case opts.contains("binaryset"):
+		return "BS"
+	}
+	return ""
+}
+
+func (opts tagOptions) timeFormat() string {
+	switch {
+	case opts.contains("unixtime"):
+		return "unixtime"
+	case opts.contains("unixmilli"):
+		return "unixmilli"
+	}
+	return ""
+}
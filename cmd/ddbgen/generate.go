@@ -0,0 +1,447 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const ddbImportPath = "github.com/runtakun/dynamodb-marshaler-go"
+
+// A config selects what generate emits.
+type config struct {
+	types []string // names of the types to generate for; nil for the marked ones
+	tags  []string
+	test  bool
+}
+
+type generator struct {
+	pkg       *pkgInfo
+	tags      []string
+	generated map[string]bool
+
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+// generate returns the source of the methods for the selected types of pkg
+// and, if cfg.test is set, of their test.
+func generate(pkg *pkgInfo, cfg config) ([]byte, []byte, error) {
+	names := cfg.types
+	if names == nil {
+		for _, name := range pkg.order {
+			if pkg.types[name].marked {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("no types marked with %s in package %s", directive, pkg.name)
+	}
+
+	g := &generator{
+		pkg:       pkg,
+		tags:      cfg.tags,
+		generated: map[string]bool{},
+		imports:   map[string]bool{"github.com/aws/aws-sdk-go/service/dynamodb": true},
+	}
+	for _, name := range names {
+		t, ok := pkg.types[name]
+		if !ok || pkg.structType(t) == nil {
+			return nil, nil, fmt.Errorf("%s is not a struct type of package %s", name, pkg.name)
+		}
+		g.generated[name] = true
+	}
+
+	for _, name := range names {
+		if err := g.generateType(pkg.types[name]); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	src, err := g.source(g.buf.Bytes(), g.imports)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !cfg.test {
+		return src, nil, nil
+	}
+
+	testSrc, err := g.source(testBody(names), map[string]bool{
+		"math/rand": true,
+		"reflect":   true,
+		"testing":   true,
+		"time":      true,
+		"github.com/aws/aws-sdk-go/service/dynamodb": true,
+		ddbImportPath: true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return src, testSrc, nil
+}
+
+// source prepends the file header and imports to body and formats it.
+func (g *generator) source(body []byte, imports map[string]bool) ([]byte, error) {
+	var paths []string
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by ddbgen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.pkg.name)
+	for _, path := range paths {
+		if !strings.Contains(path, ".") {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+	}
+	buf.WriteString("\n")
+	for _, path := range paths {
+		switch {
+		case path == ddbImportPath:
+			fmt.Fprintf(&buf, "\tddb %q\n", path)
+		case strings.Contains(path, "."):
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+	}
+	buf.WriteString(")\n")
+	buf.Write(body)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) use(path string) {
+	g.imports[path] = true
+}
+
+func (g *generator) generateType(t *typeDecl) error {
+	fields, err := typeFields(g.pkg, t, g.tags)
+	if err != nil {
+		return err
+	}
+
+	g.printf("\n// MarshalDynamoDB converts v to a dynamodb item like ddb.MarshalE.\n")
+	g.printf("func (v *%s) MarshalDynamoDB() (map[string]*dynamodb.AttributeValue, error) {\n", t.name)
+	g.printf("item := make(map[string]*dynamodb.AttributeValue, %d)\n", len(fields))
+	for _, f := range fields {
+		if err := g.marshalField(t, f); err != nil {
+			return err
+		}
+	}
+	g.printf("return item, nil\n}\n")
+
+	g.printf("\n// UnmarshalDynamoDB stores the attributes of item in v like ddb.Unmarshal.\n")
+	g.printf("func (v *%s) UnmarshalDynamoDB(item map[string]*dynamodb.AttributeValue) error {\n", t.name)
+	for _, f := range fields {
+		g.unmarshalField(f)
+	}
+	g.printf("return nil\n}\n")
+
+	return nil
+}
+
+func (g *generator) marshalField(t *typeDecl, f field) error {
+	x := "v." + strings.Join(f.sel, ".")
+	key := strconv.Quote(f.name)
+	ft := g.classify(f.typ, f.imports)
+
+	// nonEmpty is set once the emitted code has checked that x is not
+	// empty, so that the NULL cases need not be emitted.
+	nonEmpty := false
+	if f.omitEmpty {
+		test, ok := g.nonEmptyTest(f.typ, f.imports, x, false)
+		if !ok {
+			return fmt.Errorf("%s.%s: cannot tell whether a value of type %s is empty for omitempty", t.name, f.sel[len(f.sel)-1], exprString(f.typ))
+		}
+		if test != "" {
+			g.printf("if %s {\n", test)
+			defer g.printf("}\n")
+			nonEmpty = true
+		}
+	}
+
+	if f.setType != "" {
+		return g.marshalSet(t, f, x, key, nonEmpty)
+	}
+
+	// Methods are called on x itself, which may be a pointer; value is the
+	// value x points to.
+	value := x
+	if ft.ptr {
+		if !nonEmpty {
+			g.printf("if %s == nil {\n", x)
+			g.printf("item[%s] = %s\n", key, g.nullAttr())
+			g.printf("} else {\n")
+			defer g.printf("}\n")
+		}
+		value = "*" + x
+		nonEmpty = false
+	}
+
+	if ft.kind == kindTime && f.timeFormat != "" {
+		g.use("github.com/aws/aws-sdk-go/aws")
+		g.use("strconv")
+		g.use("time")
+		g.printf("item[%s] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(%s, 10))}\n", key, unixExpr(x, f.timeFormat))
+		return nil
+	}
+
+	switch ft.kind {
+	case kindString:
+		g.use("github.com/aws/aws-sdk-go/aws")
+		g.printNullIf(value+` == ""`, nonEmpty, key, "&dynamodb.AttributeValue{S: aws.String("+convert("string", ft.name, value)+")}")
+	case kindBool:
+		g.use("github.com/aws/aws-sdk-go/aws")
+		g.printf("item[%s] = &dynamodb.AttributeValue{BOOL: aws.Bool(%s)}\n", key, convert("bool", ft.name, value))
+	case kindInt, kindUint, kindFloat, kindDuration:
+		g.use("github.com/aws/aws-sdk-go/aws")
+		g.printf("item[%s] = &dynamodb.AttributeValue{N: aws.String(%s)}\n", key, g.formatNumber(ft, value))
+	case kindBytes:
+		g.printNullIf(value+" == nil", nonEmpty, key, "&dynamodb.AttributeValue{B: "+value+"}")
+	case kindTime:
+		g.use("github.com/aws/aws-sdk-go/aws")
+		g.use("time")
+		g.printf("item[%s] = &dynamodb.AttributeValue{S: aws.String(%s.Format(time.RFC3339Nano))}\n", key, x)
+	case kindStruct:
+		g.use(ddbImportPath)
+		g.printf("{\n")
+		g.printf("m, err := %s.MarshalDynamoDB()\n", x)
+		g.printf("if err != nil {\nreturn nil, ddb.WithPath(err, %s)\n}\n", key)
+		g.printf("item[%s] = &dynamodb.AttributeValue{M: m}\n", key)
+		g.printf("}\n")
+	default:
+		g.use(ddbImportPath)
+		g.printf("{\n")
+		g.printf("av, err := ddb.MarshalAttributeValue(&%s)\n", x)
+		g.printf("if err != nil {\nreturn nil, ddb.WithPath(err, %s)\n}\n", key)
+		g.printf("item[%s] = av\n", key)
+		g.printf("}\n")
+	}
+
+	return nil
+}
+
+// printNullIf emits the assignment of av to item[key], or of NULL when
+// cond holds unless the value is known not to be empty.
+func (g *generator) printNullIf(cond string, nonEmpty bool, key, av string) {
+	if nonEmpty {
+		g.printf("item[%s] = %s\n", key, av)
+		return
+	}
+	g.printf("if %s {\n", cond)
+	g.printf("item[%s] = %s\n", key, g.nullAttr())
+	g.printf("} else {\n")
+	g.printf("item[%s] = %s\n", key, av)
+	g.printf("}\n")
+}
+
+// marshalSet emits the conversion of a slice or array field with a
+// stringset, numberset or binaryset option.
+func (g *generator) marshalSet(t *typeDecl, f field, x, key string, nonEmpty bool) error {
+	var elem goType
+	if at, ok := f.typ.(*ast.ArrayType); ok {
+		elem = g.classify(at.Elt, f.imports)
+	}
+
+	// conv is the member as a string, or as a []byte for binary sets.
+	var conv, typ string
+	switch {
+	case elem.ptr:
+	case f.setType == "SS" && elem.kind == kindString,
+		f.setType == "NS" && elem.kind == kindString:
+		conv, typ = convert("string", elem.name, "e"), "[]*string"
+	case f.setType == "NS" && (elem.kind == kindInt || elem.kind == kindUint || elem.kind == kindFloat || elem.kind == kindDuration):
+		conv, typ = g.formatNumber(elem, "e"), "[]*string"
+	case f.setType == "BS" && elem.kind == kindBytes:
+		conv, typ = "e", "[][]byte"
+	}
+	if conv == "" {
+		return fmt.Errorf("%s.%s: %s of type %s is not supported", t.name, f.sel[len(f.sel)-1], f.setType, exprString(f.typ))
+	}
+
+	if typ == "[]*string" {
+		g.use("github.com/aws/aws-sdk-go/aws")
+	}
+	if !nonEmpty {
+		g.printf("if len(%s) == 0 {\n", x)
+		g.printf("item[%s] = %s\n", key, g.nullAttr())
+		g.printf("} else {\n")
+		defer g.printf("}\n")
+	}
+
+	// Duplicate members are dropped like ddb.MarshalE does, numbers by value,
+	// and strings which are not numbers are rejected from number sets.
+	g.printf("set := make(%s, 0, len(%s))\n", typ, x)
+	g.printf("seen := make(map[string]bool, len(%s))\n", x)
+	switch {
+	case typ == "[][]byte":
+		g.printf("for _, e := range %s {\n", x)
+		g.printf("if !seen[string(e)] {\nseen[string(e)] = true\nset = append(set, e)\n}\n")
+	case f.setType == "NS" && elem.kind == kindString:
+		g.use("math/big")
+		g.use("strconv")
+		g.use(ddbImportPath)
+		g.printf("for i, e := range %s {\n", x)
+		g.printf("s := %s\n", conv)
+		g.printf("n, _, err := big.ParseFloat(s, 10, 256, big.ToNearestEven)\n")
+		g.printf("if err != nil {\nreturn nil, &ddb.InvalidNumberError{Path: %s + strconv.Itoa(i) + \"]\", Number: s}\n}\n", strconv.Quote(f.name+"["))
+		g.printf("if k := n.Text('g', -1); !seen[k] {\nseen[k] = true\nset = append(set, aws.String(s))\n}\n")
+	default:
+		g.printf("for _, e := range %s {\n", x)
+		g.printf("s := %s\n", conv)
+		g.printf("if !seen[s] {\nseen[s] = true\nset = append(set, aws.String(s))\n}\n")
+	}
+	g.printf("}\n")
+	g.printf("item[%s] = &dynamodb.AttributeValue{%s: set}\n", key, f.setType)
+
+	return nil
+}
+
+func (g *generator) unmarshalField(f field) {
+	x := "v." + strings.Join(f.sel, ".")
+	key := strconv.Quote(f.name)
+	ft := g.classify(f.typ, f.imports)
+
+	g.printf("if av, ok := item[%s]; ok {\n", key)
+	defer g.printf("}\n")
+
+	alloc := ""
+	if ft.ptr {
+		alloc = fmt.Sprintf("if %s == nil {\n%s = new(%s)\n}\n", x, x, ft.name)
+	}
+	target := x
+	if ft.ptr {
+		target = "*" + x
+	}
+
+	if ft.kind == kindTime && f.timeFormat != "" {
+		g.use("strconv")
+		g.use("time")
+		g.use(ddbImportPath)
+		zero := "time.Time{}"
+		if ft.ptr {
+			zero = "nil"
+		}
+		g.printf("if av.NULL != nil {\n%s = %s\n} else {\n", x, zero)
+		defer g.printf("}\n")
+		g.printf("%s", alloc)
+		g.printf("if av.S != nil {\n")
+		g.printf("t, err := time.Parse(time.RFC3339Nano, *av.S)\n")
+		g.printf("if err != nil {\nreturn &ddb.UnmarshalTimeError{Path: %s, Value: *av.S, Err: err}\n}\n", key)
+		g.printf("%s = t\n", target)
+		g.printf("} else if av.N != nil {\n")
+		g.printf("n, err := strconv.ParseInt(*av.N, 10, 64)\n")
+		g.printf("if err != nil {\nif err := ddb.UnmarshalAttributeValue(av, &n); err != nil {\nreturn ddb.WithPath(err, %s)\n}\n}\n", key)
+		if f.timeFormat == "unixmilli" {
+			g.printf("%s = time.Unix(n/1000, n%%1000*int64(time.Millisecond))\n", target)
+		} else {
+			g.printf("%s = time.Unix(n, 0)\n", target)
+		}
+		g.printf("}\n")
+		return
+	}
+
+	switch ft.kind {
+	case kindString:
+		g.printf("if av.S != nil {\n%s%s = %s\n", alloc, target, convert(ft.name, "string", "*av.S"))
+	case kindBool:
+		g.printf("if av.BOOL != nil {\n%s%s = %s\n", alloc, target, convert(ft.name, "bool", "*av.BOOL"))
+	case kindInt, kindUint, kindFloat, kindDuration:
+		g.use("github.com/aws/aws-sdk-go/aws")
+		g.use("strconv")
+		if ft.kind == kindDuration {
+			g.use("time")
+		}
+		parse, typ := g.parseNumber(ft)
+		g.printf("if n, err := %s; av.N != nil && err == nil {\n%s%s = %s\n", parse, alloc, target, convert(ft.name, typ, "n"))
+	case kindBytes:
+		g.printf("if av.B != nil {\n%s%s = av.B\n", alloc, target)
+	case kindTime:
+		g.use("github.com/aws/aws-sdk-go/aws")
+		g.use("time")
+		g.printf("if t, err := time.Parse(time.RFC3339Nano, aws.StringValue(av.S)); av.S != nil && err == nil {\n%s%s = t\n", alloc, target)
+	case kindStruct:
+		g.printf("if av.M != nil {\n")
+		g.printf("var m %s\n", ft.name)
+		g.use(ddbImportPath)
+		g.printf("if err := m.UnmarshalDynamoDB(av.M); err != nil {\nreturn ddb.WithPath(err, %s)\n}\n", key)
+		g.printf("%s%s = m\n", alloc, target)
+	default:
+		g.use(ddbImportPath)
+		g.printf("if err := ddb.UnmarshalAttributeValue(av, &%s); err != nil {\nreturn ddb.WithPath(err, %s)\n}\n", x, key)
+		return
+	}
+
+	g.use(ddbImportPath)
+	g.printf("} else if err := ddb.UnmarshalAttributeValue(av, &%s); err != nil {\nreturn ddb.WithPath(err, %s)\n}\n", x, key)
+}
+
+func (g *generator) nullAttr() string {
+	g.use("github.com/aws/aws-sdk-go/aws")
+	return "&dynamodb.AttributeValue{NULL: aws.Bool(true)}"
+}
+
+// formatNumber returns the expression formatting the number x of type t
+// the way package ddb does.
+func (g *generator) formatNumber(t goType, x string) string {
+	g.use("strconv")
+	switch t.kind {
+	case kindUint:
+		return "strconv.FormatUint(" + convert("uint64", t.name, x) + ", 10)"
+	case kindFloat:
+		return "strconv.FormatFloat(" + convert("float64", t.name, x) + ", 'f', -1, 64)"
+	}
+	return "strconv.FormatInt(" + convert("int64", t.name, x) + ", 10)"
+}
+
+// parseNumber returns the expression parsing av.N into a value of type t,
+// and the type of that value.
+func (g *generator) parseNumber(t goType) (string, string) {
+	switch t.kind {
+	case kindUint:
+		return fmt.Sprintf("strconv.ParseUint(aws.StringValue(av.N), 10, %d)", t.bits), "uint64"
+	case kindFloat:
+		return fmt.Sprintf("strconv.ParseFloat(aws.StringValue(av.N), %d)", t.bits), "float64"
+	}
+	return fmt.Sprintf("strconv.ParseInt(aws.StringValue(av.N), 10, %d)", t.bits), "int64"
+}
+
+func unixExpr(x, format string) string {
+	if format == "unixmilli" {
+		return x + ".Unix()*1000 + int64(" + x + ".Nanosecond())/int64(time.Millisecond)"
+	}
+	return x + ".Unix()"
+}
+
+// convert returns x converted from type from to type to, leaving out
+// conversions between identical types.
+func convert(to, from, x string) string {
+	if to == from {
+		return x
+	}
+	return to + "(" + x + ")"
+}
+
+var emptyFileSet = token.NewFileSet()
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, emptyFileSet, expr)
+	return buf.String()
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var defaultConfig = config{tags: []string{"dynamodb"}}
+
+// generateSource generates the methods for the package made of src.
+func generateSource(src string, cfg config) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "ddbgen")
+	Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "types.go"), []byte(src), 0644)
+	Expect(err).NotTo(HaveOccurred())
+
+	pkg, err := loadPackage(dir)
+	Expect(err).NotTo(HaveOccurred())
+
+	out, _, err := generate(pkg, cfg)
+	return out, err
+}
+
+var _ = Describe("Generate", func() {
+
+	It("should keep the example package up to date", func() {
+		pkg, err := loadPackage("example")
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg := defaultConfig
+		cfg.test = true
+		src, testSrc, err := generate(pkg, cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		want, err := ioutil.ReadFile(filepath.Join("example", "example_ddbgen.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(src)).To(Equal(string(want)), "run go generate in cmd/ddbgen/example")
+
+		wantTest, err := ioutil.ReadFile(filepath.Join("example", "example_ddbgen_test.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(testSrc)).To(Equal(string(wantTest)), "run go generate in cmd/ddbgen/example")
+	})
+
+	It("should generate only for the listed types", func() {
+		cfg := defaultConfig
+		cfg.types = []string{"b"}
+		src, err := generateSource(`package p
+
+//ddb:generate
+type a struct{ Name string }
+
+type b struct{ Name string }
+`, cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(src)).To(ContainSubstring("func (v *b) MarshalDynamoDB()"))
+		Expect(string(src)).NotTo(ContainSubstring("func (v *a) MarshalDynamoDB()"))
+	})
+
+	It("should read the configured tag keys", func() {
+		cfg := defaultConfig
+		cfg.tags = []string{"dynamodbav"}
+		src, err := generateSource(`package p
+
+//ddb:generate
+type a struct {
+	Name string `+"`dynamodb:\"name\" dynamodbav:\"n\"`"+`
+}
+`, cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(src)).To(ContainSubstring(`item["n"]`))
+	})
+
+	It("should convert types implementing ddb.Marshaler by reflection", func() {
+		src, err := generateSource(`package p
+
+import "github.com/aws/aws-sdk-go/service/dynamodb"
+
+type money int64
+
+func (m money) MarshalDynamoDBAttributeValue() (*dynamodb.AttributeValue, error) { return nil, nil }
+
+//ddb:generate
+type a struct{ Price money }
+`, defaultConfig)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(src)).To(ContainSubstring("ddb.MarshalAttributeValue(&v.Price)"))
+	})
+
+	It("should fail without marked types", func() {
+		_, err := generateSource("package p\n\ntype a struct{ Name string }\n", defaultConfig)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail for a type which is not a struct", func() {
+		cfg := defaultConfig
+		cfg.types = []string{"a"}
+		_, err := generateSource("package p\n\ntype a string\n", cfg)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail for omitempty on a type of another package", func() {
+		_, err := generateSource(`package p
+
+import "net/url"
+
+//ddb:generate
+type a struct {
+	URL url.URL `+"`dynamodb:\"url,omitempty\"`"+`
+}
+`, defaultConfig)
+		Expect(err).To(MatchError(ContainSubstring("a.URL")))
+	})
+
+	It("should fail for an unsupported set", func() {
+		_, err := generateSource(`package p
+
+//ddb:generate
+type a struct {
+	Tags map[string]bool `+"`dynamodb:\"tags,stringset\"`"+`
+}
+`, defaultConfig)
+		Expect(err).To(MatchError(ContainSubstring("a.Tags")))
+	})
+
+	It("should validate and compare number set members of string type by value", func() {
+		src, err := generateSource(`package p
+
+//ddb:generate
+type a struct {
+	Prices []string `+"`dynamodb:\"prices,numberset\"`"+`
+}
+`, defaultConfig)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(src)).To(ContainSubstring(`"math/big"`))
+		Expect(string(src)).To(ContainSubstring("big.ParseFloat(s, 10, 256, big.ToNearestEven)"))
+		Expect(string(src)).To(ContainSubstring(`&ddb.InvalidNumberError{Path: "prices[" + strconv.Itoa(i) + "]", Number: s}`))
+	})
+
+	It("should fail for an embedded pointer", func() {
+		_, err := generateSource(`package p
+
+type Base struct{ ID string }
+
+//ddb:generate
+type a struct {
+	*Base
+}
+`, defaultConfig)
+		Expect(err).To(MatchError(ContainSubstring("Base")))
+	})
+})
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// directive marks a type declaration for which methods are generated.
+const directive = "//ddb:generate"
+
+// A pkgInfo holds the type declarations of the package being generated.
+type pkgInfo struct {
+	name  string
+	types map[string]*typeDecl
+	order []string // type names in source order
+}
+
+// A typeDecl is a type declared at package level.
+type typeDecl struct {
+	name    string
+	expr    ast.Expr
+	imports map[string]string // import names of the declaring file to paths
+	marked  bool              // the doc comment holds the directive
+	custom  bool              // a ddb.Marshaler or ddb.Unmarshaler method is declared
+}
+
+// loadPackage parses the non-test Go files of the package in dir.
+func loadPackage(dir string) (*pkgInfo, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &pkgInfo{name: bp.Name, types: map[string]*typeDecl{}}
+	methods := map[string]bool{}
+
+	fset := token.NewFileSet()
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		imports := fileImports(f)
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				if decl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					ts := spec.(*ast.TypeSpec)
+					doc := ts.Doc
+					if doc == nil && len(decl.Specs) == 1 {
+						doc = decl.Doc
+					}
+					pkg.types[ts.Name.Name] = &typeDecl{
+						name:    ts.Name.Name,
+						expr:    ts.Type,
+						imports: imports,
+						marked:  hasDirective(doc),
+					}
+					pkg.order = append(pkg.order, ts.Name.Name)
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil || len(decl.Recv.List) != 1 {
+					continue
+				}
+				switch decl.Name.Name {
+				case "MarshalDynamoDBAttributeValue", "UnmarshalDynamoDBAttributeValue":
+					methods[receiverName(decl.Recv.List[0].Type)] = true
+				}
+			}
+		}
+	}
+
+	for name := range methods {
+		if t, ok := pkg.types[name]; ok {
+			t.custom = true
+		}
+	}
+
+	return pkg, nil
+}
+
+func fileImports(f *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+func hasDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == directive {
+			return true
+		}
+	}
+	return false
+}
+
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
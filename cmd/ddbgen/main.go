@@ -0,0 +1,106 @@
+// Command ddbgen generates reflection-free MarshalDynamoDB and
+// UnmarshalDynamoDB methods for struct types, producing the same attribute
+// values as ddb.MarshalE and ddb.Unmarshal with their default options. Like
+// ddb.MarshalE, MarshalDynamoDB returns an error for values of unsupported
+// types, which ddb.Marshal leaves out.
+//
+// Mark the types with a //ddb:generate line in their doc comment, or list
+// them with -type, and run ddbgen from go generate:
+//
+//	//go:generate ddbgen
+//
+//	//ddb:generate
+//	type User struct {
+//		ID   string `dynamodb:"id"`
+//		Name string `dynamodb:"name,omitempty"`
+//	}
+//
+// The methods are written to <package>_ddbgen.go. Fields of basic types,
+// []byte, time.Time, time.Duration, sets and other generated structs of the
+// package are converted directly; any other field is converted by
+// ddb.MarshalAttributeValue and ddb.UnmarshalAttributeValue, so it behaves
+// as with reflection. Errors of the generated methods report attribute paths
+// relative to the field rather than to the item.
+//
+// With -test ddbgen also writes <package>_ddbgen_test.go, which fills each
+// type with random values and checks that the generated methods agree with
+// ddb.MarshalE and ddb.Unmarshal.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of type names; default is the types marked with "+directive)
+	tagNames  = flag.String("tags", "dynamodb", "comma-separated list of struct tag keys read for attribute names")
+	output    = flag.String("output", "", "output file name; default <package>_ddbgen.go")
+	withTest  = flag.Bool("test", false, "also write a test comparing the generated methods with ddb.MarshalE and ddb.Unmarshal")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: ddbgen [flags] [directory]\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	dir := "."
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		dir = flag.Arg(0)
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err := run(dir); err != nil {
+		fmt.Fprintln(os.Stderr, "ddbgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string) error {
+	cfg := config{
+		tags: strings.Split(*tagNames, ","),
+		test: *withTest,
+	}
+	if *typeNames != "" {
+		cfg.types = strings.Split(*typeNames, ",")
+	}
+
+	pkg, err := loadPackage(dir)
+	if err != nil {
+		return err
+	}
+
+	src, testSrc, err := generate(pkg, cfg)
+	if err != nil {
+		return err
+	}
+
+	name := *output
+	if name == "" {
+		name = pkg.name + "_ddbgen.go"
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name), src, 0644); err != nil {
+		return err
+	}
+
+	if testSrc != nil {
+		testName := strings.TrimSuffix(name, ".go") + "_test.go"
+		if err := ioutil.WriteFile(filepath.Join(dir, testName), testSrc, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// testBody returns the test written with -test. For each type it compares
+// the generated methods with ddb.MarshalE and ddb.Unmarshal on values filled
+// from a seeded random source.
+func testBody(names []string) []byte {
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\nfunc TestDDBGen%s(t *testing.T) {\n", name)
+		fmt.Fprintf(&buf, "ddbgenCheck(t, func() ddbgenItem { return new(%s) })\n}\n", name)
+	}
+	buf.WriteString(testHelpers)
+	return buf.Bytes()
+}
+
+const testHelpers = `
+type ddbgenItem interface {
+	MarshalDynamoDB() (map[string]*dynamodb.AttributeValue, error)
+	UnmarshalDynamoDB(map[string]*dynamodb.AttributeValue) error
+}
+
+func ddbgenCheck(t *testing.T, newItem func() ddbgenItem) {
+	for seed := int64(0); seed < 200; seed++ {
+		v := newItem()
+		ddbgenFill(reflect.ValueOf(v).Elem(), rand.New(rand.NewSource(seed)), 0)
+
+		want, wantErr := ddb.MarshalE(v)
+		got, err := v.MarshalDynamoDB()
+		if (err != nil) != (wantErr != nil) {
+			t.Fatalf("seed %d: MarshalDynamoDB() error = %v, ddb.MarshalE error = %v", seed, err, wantErr)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("seed %d: MarshalDynamoDB() = %v, ddb.MarshalE = %v", seed, got, want)
+		}
+		if want == nil {
+			continue
+		}
+
+		// Decode into values holding other data, so that fields missing
+		// from the item must be kept alike too.
+		w, g := newItem(), newItem()
+		ddbgenFill(reflect.ValueOf(w).Elem(), rand.New(rand.NewSource(-seed-1)), 0)
+		ddbgenFill(reflect.ValueOf(g).Elem(), rand.New(rand.NewSource(-seed-1)), 0)
+
+		wantErr = ddb.Unmarshal(want, w)
+		err = g.UnmarshalDynamoDB(want)
+		if (err != nil) != (wantErr != nil) {
+			t.Fatalf("seed %d: UnmarshalDynamoDB() error = %v, ddb.Unmarshal error = %v", seed, err, wantErr)
+		}
+		if !reflect.DeepEqual(g, w) {
+			t.Fatalf("seed %d: UnmarshalDynamoDB() = %+v, ddb.Unmarshal = %+v", seed, g, w)
+		}
+	}
+}
+
+// ddbgenFill sets the exported fields reachable from v to random values,
+// leaving some of them zero.
+func ddbgenFill(v reflect.Value, r *rand.Rand, depth int) {
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		if r.Intn(4) != 0 {
+			v.Set(reflect.ValueOf(time.Unix(r.Int63n(1<<33), r.Int63n(1e9)).UTC()))
+		}
+		return
+	}
+	if depth > 4 || (depth > 0 && r.Intn(4) == 0) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString([]string{"", "a", "foo", "Foo Bar", "1.5"}[r.Intn(5)])
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(r.Int63() >> uint(64-v.Type().Bits()) * int64(1-2*r.Intn(2)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(r.Int63()) >> uint(64-v.Type().Bits()) * uint64(1+r.Intn(2)))
+	case reflect.Float32:
+		v.SetFloat(float64(float32(r.NormFloat64() * 1e3)))
+	case reflect.Float64:
+		v.SetFloat(r.NormFloat64() * 1e6)
+	case reflect.Slice:
+		if r.Intn(4) == 0 {
+			return
+		}
+		n := r.Intn(4)
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			ddbgenFill(v.Index(i), r, depth+1)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			ddbgenFill(v.Index(i), r, depth+1)
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		for i := r.Intn(4); i > 0; i-- {
+			key := reflect.New(v.Type().Key()).Elem()
+			elem := reflect.New(v.Type().Elem()).Elem()
+			ddbgenFill(key, r, depth+1)
+			ddbgenFill(elem, r, depth+1)
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		ddbgenFill(v.Elem(), r, depth+1)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				ddbgenFill(v.Field(i), r, depth+1)
+			}
+		}
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf([]string{"", "foo", "bar"}[r.Intn(3)]))
+		}
+	}
+}
+`
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"go/ast"
+)
+
+// A kind is the class of Go types a field is converted as.
+type kind int
+
+const (
+	kindOther    kind = iota // converted by ddb.MarshalAttributeValue and ddb.UnmarshalAttributeValue
+	kindString               // string kinds
+	kindBool                 // bool kinds
+	kindInt                  // signed integer kinds
+	kindUint                 // unsigned integer kinds
+	kindFloat                // floating-point kinds
+	kindBytes                // []byte
+	kindTime                 // time.Time
+	kindDuration             // time.Duration
+	kindStruct               // struct types the methods are generated for
+)
+
+// A goType describes the type of a field as far as the generated code
+// needs it.
+type goType struct {
+	kind kind
+	name string // the type as spelled in conversions
+	bits int    // bit size for numbers, 0 for int and uint
+	ptr  bool   // a pointer to the type described
+}
+
+var basicTypes = map[string]goType{
+	"string":  {kind: kindString},
+	"bool":    {kind: kindBool},
+	"int":     {kind: kindInt},
+	"int8":    {kind: kindInt, bits: 8},
+	"int16":   {kind: kindInt, bits: 16},
+	"int32":   {kind: kindInt, bits: 32},
+	"rune":    {kind: kindInt, bits: 32},
+	"int64":   {kind: kindInt, bits: 64},
+	"uint":    {kind: kindUint},
+	"uint8":   {kind: kindUint, bits: 8},
+	"byte":    {kind: kindUint, bits: 8},
+	"uint16":  {kind: kindUint, bits: 16},
+	"uint32":  {kind: kindUint, bits: 32},
+	"uint64":  {kind: kindUint, bits: 64},
+	"float32": {kind: kindFloat, bits: 32},
+	"float64": {kind: kindFloat, bits: 64},
+}
+
+// classify describes the type expr. Types the generated code cannot
+// convert directly, including types implementing ddb.Marshaler or
+// ddb.Unmarshaler, are kindOther.
+func (g *generator) classify(expr ast.Expr, imports map[string]string) goType {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return g.classify(expr.X, imports)
+	case *ast.StarExpr:
+		t := g.classify(expr.X, imports)
+		if t.ptr || t.kind == kindOther {
+			return goType{}
+		}
+		t.ptr = true
+		return t
+	case *ast.Ident:
+		if t, ok := basicTypes[expr.Name]; ok && g.pkg.types[expr.Name] == nil {
+			t.name = expr.Name
+			return t
+		}
+		decl := g.pkg.types[expr.Name]
+		if decl == nil || decl.custom {
+			return goType{}
+		}
+		if g.generated[expr.Name] {
+			return goType{kind: kindStruct, name: expr.Name}
+		}
+		t := g.classify(decl.expr, decl.imports)
+		switch {
+		case t.ptr:
+			return goType{}
+		case t.kind == kindDuration:
+			return goType{kind: kindInt, name: expr.Name, bits: 64}
+		case t.kind == kindString, t.kind == kindBool, t.kind == kindInt, t.kind == kindUint, t.kind == kindFloat:
+			t.name = expr.Name
+			return t
+		}
+	case *ast.SelectorExpr:
+		if x, ok := expr.X.(*ast.Ident); ok && imports[x.Name] == "time" {
+			switch expr.Sel.Name {
+			case "Time":
+				return goType{kind: kindTime, name: "time.Time"}
+			case "Duration":
+				return goType{kind: kindDuration, name: "time.Duration", bits: 64}
+			}
+		}
+	case *ast.ArrayType:
+		if elem, ok := expr.Elt.(*ast.Ident); ok && expr.Len == nil && (elem.Name == "byte" || elem.Name == "uint8") && g.pkg.types[elem.Name] == nil {
+			return goType{kind: kindBytes, name: "[]byte"}
+		}
+	}
+	return goType{}
+}
+
+// nonEmptyTest returns the condition under which x of type expr is not
+// empty in the sense of the omitempty option, or "" if it never is. It
+// reports false if the type is declared in another package, so that its
+// kind is unknown. named is set for the underlying type of a named type.
+func (g *generator) nonEmptyTest(expr ast.Expr, imports map[string]string, x string, named bool) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return g.nonEmptyTest(expr.X, imports, x, named)
+	case *ast.StarExpr, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return x + " != nil", true
+	case *ast.ArrayType, *ast.MapType:
+		return "len(" + x + ") != 0", true
+	case *ast.StructType:
+		return "", true
+	case *ast.Ident:
+		if decl := g.pkg.types[expr.Name]; decl != nil {
+			return g.nonEmptyTest(decl.expr, decl.imports, x, true)
+		}
+		switch expr.Name {
+		case "string":
+			return x + ` != ""`, true
+		case "bool":
+			return x, true
+		case "error", "any":
+			return x + " != nil", true
+		case "complex64", "complex128":
+			return "", true
+		}
+		return x + " != 0", true
+	case *ast.SelectorExpr:
+		if pkg, ok := expr.X.(*ast.Ident); ok && imports[pkg.Name] == "time" {
+			switch {
+			case expr.Sel.Name == "Duration":
+				return x + " != 0", true
+			case expr.Sel.Name == "Time" && named:
+				return "", true
+			case expr.Sel.Name == "Time":
+				return "!" + x + ".IsZero()", true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,170 @@
+// Package ddbstream decodes the item images of DynamoDB Streams records
+// delivered to AWS Lambda, as represented by aws-lambda-go, so that the
+// structs used with package ddb for table access also serve stream
+// handlers.
+package ddbstream
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	ddb "github.com/runtakun/dynamodb-marshaler-go"
+)
+
+// UnmarshalStreamImage converts the NewImage, OldImage or Keys of a stream
+// record to map or struct like ddb.Unmarshal.
+func UnmarshalStreamImage(image map[string]events.DynamoDBAttributeValue, v interface{}, opts ...ddb.DecodeOption) error {
+	item, err := ToV1Item(image)
+	if err != nil {
+		return err
+	}
+	return ddb.Unmarshal(item, v, opts...)
+}
+
+// ToV1 converts a stream attribute value to an aws-sdk-go attribute value.
+// It fails on a zero DynamoDBAttributeValue, which holds no value, and on
+// data types it does not know.
+func ToV1(av events.DynamoDBAttributeValue) (*dynamodb.AttributeValue, error) {
+	// The zero value claims to be a binary without holding one, so Binary
+	// would panic on it.
+	if av == (events.DynamoDBAttributeValue{}) {
+		return nil, errors.New("ddbstream: invalid attribute value: zero DynamoDBAttributeValue")
+	}
+
+	switch av.DataType() {
+	case events.DataTypeString:
+		s := av.String()
+		return &dynamodb.AttributeValue{S: &s}, nil
+	case events.DataTypeNumber:
+		n := av.Number()
+		return &dynamodb.AttributeValue{N: &n}, nil
+	case events.DataTypeBinary:
+		b := av.Binary()
+		if b == nil {
+			b = []byte{}
+		}
+		return &dynamodb.AttributeValue{B: b}, nil
+	case events.DataTypeBoolean:
+		b := av.Boolean()
+		return &dynamodb.AttributeValue{BOOL: &b}, nil
+	case events.DataTypeNull:
+		null := true
+		return &dynamodb.AttributeValue{NULL: &null}, nil
+	case events.DataTypeStringSet:
+		return &dynamodb.AttributeValue{SS: stringPtrs(av.StringSet())}, nil
+	case events.DataTypeNumberSet:
+		return &dynamodb.AttributeValue{NS: stringPtrs(av.NumberSet())}, nil
+	case events.DataTypeBinarySet:
+		bs := av.BinarySet()
+		if bs == nil {
+			bs = [][]byte{}
+		}
+		return &dynamodb.AttributeValue{BS: bs}, nil
+	case events.DataTypeList:
+		l := make([]*dynamodb.AttributeValue, len(av.List()))
+		for i, elem := range av.List() {
+			v1, err := ToV1(elem)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = v1
+		}
+		return &dynamodb.AttributeValue{L: l}, nil
+	case events.DataTypeMap:
+		m := make(map[string]*dynamodb.AttributeValue, len(av.Map()))
+		for k, elem := range av.Map() {
+			v1, err := ToV1(elem)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v1
+		}
+		return &dynamodb.AttributeValue{M: m}, nil
+	}
+
+	return nil, fmt.Errorf("ddbstream: invalid attribute value: unsupported data type %v", av.DataType())
+}
+
+func stringPtrs(ss []string) []*string {
+	ptrs := make([]*string, len(ss))
+	for i := range ss {
+		ptrs[i] = &ss[i]
+	}
+	return ptrs
+}
+
+// ToV1Item converts a stream image to an aws-sdk-go item.
+func ToV1Item(image map[string]events.DynamoDBAttributeValue) (map[string]*dynamodb.AttributeValue, error) {
+	if image == nil {
+		return nil, nil
+	}
+
+	item := make(map[string]*dynamodb.AttributeValue, len(image))
+	for k, av := range image {
+		v1, err := ToV1(av)
+		if err != nil {
+			return nil, err
+		}
+		item[k] = v1
+	}
+	return item, nil
+}
+
+// FromV1 converts an aws-sdk-go attribute value to a stream attribute value.
+// An attribute value with no member set converts to NULL.
+func FromV1(av *dynamodb.AttributeValue) events.DynamoDBAttributeValue {
+	if av == nil {
+		return events.NewNullAttribute()
+	}
+
+	switch {
+	case av.S != nil:
+		return events.NewStringAttribute(*av.S)
+	case av.N != nil:
+		return events.NewNumberAttribute(*av.N)
+	case av.B != nil:
+		return events.NewBinaryAttribute(av.B)
+	case av.BOOL != nil:
+		return events.NewBooleanAttribute(*av.BOOL)
+	case av.SS != nil:
+		return events.NewStringSetAttribute(derefStrings(av.SS))
+	case av.NS != nil:
+		return events.NewNumberSetAttribute(derefStrings(av.NS))
+	case av.BS != nil:
+		return events.NewBinarySetAttribute(av.BS)
+	case av.L != nil:
+		l := make([]events.DynamoDBAttributeValue, len(av.L))
+		for i, elem := range av.L {
+			l[i] = FromV1(elem)
+		}
+		return events.NewListAttribute(l)
+	case av.M != nil:
+		return events.NewMapAttribute(FromV1Item(av.M))
+	}
+
+	return events.NewNullAttribute()
+}
+
+func derefStrings(ptrs []*string) []string {
+	ss := make([]string, len(ptrs))
+	for i, p := range ptrs {
+		ss[i] = *p
+	}
+	return ss
+}
+
+// FromV1Item converts an aws-sdk-go item to a stream image.
+func FromV1Item(item map[string]*dynamodb.AttributeValue) map[string]events.DynamoDBAttributeValue {
+	if item == nil {
+		return nil
+	}
+
+	image := make(map[string]events.DynamoDBAttributeValue, len(item))
+	for k, av := range item {
+		image[k] = FromV1(av)
+	}
+	return image
+}
@@ -0,0 +1,13 @@
+package ddbstream_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDdbstream(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ddbstream Suite")
+}
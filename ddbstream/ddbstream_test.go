@@ -0,0 +1,141 @@
+package ddbstream_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	ddb "github.com/runtakun/dynamodb-marshaler-go"
+	. "github.com/runtakun/dynamodb-marshaler-go/ddbstream"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type order struct {
+	ID        string            `dynamodb:"id"`
+	Total     float64           `dynamodb:"total"`
+	Paid      bool              `dynamodb:"paid"`
+	Note      *string           `dynamodb:"note"`
+	Receipt   []byte            `dynamodb:"receipt"`
+	Tags      []string          `dynamodb:"tags,stringset"`
+	Scores    map[int]struct{}  `dynamodb:"scores"`
+	Lines     []string          `dynamodb:"lines"`
+	Shipping  map[string]string `dynamodb:"shipping"`
+	ExpiresAt time.Time         `dynamodb:"expires_at,unixtime"`
+}
+
+const record = `{
+	"Records": [{
+		"eventName": "MODIFY",
+		"dynamodb": {
+			"Keys": {"id": {"S": "o-1"}},
+			"NewImage": {
+				"id": {"S": "o-1"},
+				"total": {"N": "12.5"},
+				"paid": {"BOOL": true},
+				"note": {"NULL": true},
+				"receipt": {"B": "AQI="},
+				"tags": {"SS": ["a", "b"]},
+				"scores": {"NS": ["1", "3"]},
+				"lines": {"L": [{"S": "x"}, {"NULL": true}]},
+				"shipping": {"M": {"city": {"S": "Tokyo"}}},
+				"expires_at": {"N": "1500000000"}
+			}
+		}
+	}]
+}`
+
+var _ = Describe("Ddbstream", func() {
+
+	var event events.DynamoDBEvent
+
+	BeforeEach(func() {
+		Expect(json.Unmarshal([]byte(record), &event)).To(Succeed())
+	})
+
+	Context("UnmarshalStreamImage", func() {
+		It("should decode a new image", func() {
+			var sut order
+			Expect(UnmarshalStreamImage(event.Records[0].Change.NewImage, &sut)).To(Succeed())
+
+			Expect(sut.ID).To(Equal("o-1"))
+			Expect(sut.Total).To(Equal(12.5))
+			Expect(sut.Paid).To(BeTrue())
+			Expect(sut.Note).To(BeNil())
+			Expect(sut.Receipt).To(Equal([]byte{0x1, 0x2}))
+			Expect(sut.Tags).To(Equal([]string{"a", "b"}))
+			Expect(sut.Scores).To(Equal(map[int]struct{}{1: {}, 3: {}}))
+			Expect(sut.Lines).To(Equal([]string{"x", ""}))
+			Expect(sut.Shipping).To(Equal(map[string]string{"city": "Tokyo"}))
+			Expect(sut.ExpiresAt.Equal(time.Unix(1500000000, 0))).To(BeTrue())
+		})
+
+		It("should decode keys", func() {
+			var sut order
+			Expect(UnmarshalStreamImage(event.Records[0].Change.Keys, &sut)).To(Succeed())
+			Expect(sut.ID).To(Equal("o-1"))
+		})
+
+		It("should apply options", func() {
+			var sut struct {
+				ID int `dynamodb:"id"`
+			}
+			err := UnmarshalStreamImage(event.Records[0].Change.Keys, &sut, ddb.Strict())
+			Expect(err).To(BeAssignableToTypeOf(&ddb.UnmarshalTypeError{}))
+		})
+
+		It("should report zero attribute value", func() {
+			var sut order
+			err := UnmarshalStreamImage(map[string]events.DynamoDBAttributeValue{"id": {}}, &sut)
+			Expect(err).To(HaveOccurred())
+
+			_, err = ToV1(events.NewListAttribute([]events.DynamoDBAttributeValue{{}}))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should convert an empty binary", func() {
+			sut, err := ToV1(events.NewBinaryAttribute(nil))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut.B).To(Equal([]byte{}))
+		})
+	})
+
+	Context("conversion", func() {
+		It("should match the item marshaled for the table", func() {
+			var sut order
+			Expect(UnmarshalStreamImage(event.Records[0].Change.NewImage, &sut)).To(Succeed())
+
+			item, err := ToV1Item(event.Records[0].Change.NewImage)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ddb.Marshal(&sut)).To(Equal(item))
+		})
+
+		It("should convert aws-sdk-go values to stream values", func() {
+			image := FromV1Item(map[string]*dynamodb.AttributeValue{
+				"s":    {S: aws.String("foo")},
+				"ns":   {NS: []*string{aws.String("1")}},
+				"l":    {L: []*dynamodb.AttributeValue{{BOOL: aws.Bool(true)}}},
+				"null": {NULL: aws.Bool(true)},
+			})
+
+			Expect(image["s"].String()).To(Equal("foo"))
+			Expect(image["ns"].NumberSet()).To(Equal([]string{"1"}))
+			Expect(image["l"].List()[0].Boolean()).To(BeTrue())
+			Expect(image["null"].IsNull()).To(BeTrue())
+		})
+
+		It("should round trip", func() {
+			item := map[string]*dynamodb.AttributeValue{
+				"b":  {B: []byte{0x1}},
+				"bs": {BS: [][]byte{{0x1}, {0x2}}},
+				"m":  {M: map[string]*dynamodb.AttributeValue{"n": {N: aws.String("1")}}},
+			}
+			sut, err := ToV1Item(FromV1Item(item))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut).To(Equal(item))
+		})
+	})
+})
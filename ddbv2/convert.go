@@ -0,0 +1,147 @@
+package ddbv2
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// FromV1 converts an aws-sdk-go attribute value to its aws-sdk-go-v2
+// counterpart. It returns nil for nil or an attribute value with no member
+// set.
+func FromV1(av *dynamodb.AttributeValue) types.AttributeValue {
+	if av == nil {
+		return nil
+	}
+
+	switch {
+	case av.S != nil:
+		return &types.AttributeValueMemberS{Value: *av.S}
+	case av.N != nil:
+		return &types.AttributeValueMemberN{Value: *av.N}
+	case av.B != nil:
+		return &types.AttributeValueMemberB{Value: av.B}
+	case av.BOOL != nil:
+		return &types.AttributeValueMemberBOOL{Value: *av.BOOL}
+	case av.NULL != nil:
+		return &types.AttributeValueMemberNULL{Value: *av.NULL}
+	case av.SS != nil:
+		ss := make([]string, len(av.SS))
+		for i, s := range av.SS {
+			ss[i] = *s
+		}
+		return &types.AttributeValueMemberSS{Value: ss}
+	case av.NS != nil:
+		ns := make([]string, len(av.NS))
+		for i, n := range av.NS {
+			ns[i] = *n
+		}
+		return &types.AttributeValueMemberNS{Value: ns}
+	case av.BS != nil:
+		return &types.AttributeValueMemberBS{Value: av.BS}
+	case av.L != nil:
+		l := make([]types.AttributeValue, len(av.L))
+		for i, elem := range av.L {
+			l[i] = FromV1(elem)
+		}
+		return &types.AttributeValueMemberL{Value: l}
+	case av.M != nil:
+		return &types.AttributeValueMemberM{Value: FromV1Item(av.M)}
+	}
+
+	return nil
+}
+
+// FromV1Item converts an aws-sdk-go item to an aws-sdk-go-v2 item.
+func FromV1Item(item map[string]*dynamodb.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+
+	m := make(map[string]types.AttributeValue, len(item))
+	for k, av := range item {
+		m[k] = FromV1(av)
+	}
+	return m
+}
+
+// ToV1 converts an aws-sdk-go-v2 attribute value to its aws-sdk-go
+// counterpart. Empty members convert to empty, not nil, slices and maps so
+// that their type is kept. It fails on union members unknown to the SDK.
+func ToV1(av types.AttributeValue) (*dynamodb.AttributeValue, error) {
+	switch av := av.(type) {
+	case nil:
+		return nil, nil
+	case *types.AttributeValueMemberS:
+		return &dynamodb.AttributeValue{S: &av.Value}, nil
+	case *types.AttributeValueMemberN:
+		return &dynamodb.AttributeValue{N: &av.Value}, nil
+	case *types.AttributeValueMemberB:
+		b := av.Value
+		if b == nil {
+			b = []byte{}
+		}
+		return &dynamodb.AttributeValue{B: b}, nil
+	case *types.AttributeValueMemberBOOL:
+		return &dynamodb.AttributeValue{BOOL: &av.Value}, nil
+	case *types.AttributeValueMemberNULL:
+		return &dynamodb.AttributeValue{NULL: &av.Value}, nil
+	case *types.AttributeValueMemberSS:
+		ss := make([]*string, len(av.Value))
+		for i := range av.Value {
+			ss[i] = &av.Value[i]
+		}
+		return &dynamodb.AttributeValue{SS: ss}, nil
+	case *types.AttributeValueMemberNS:
+		ns := make([]*string, len(av.Value))
+		for i := range av.Value {
+			ns[i] = &av.Value[i]
+		}
+		return &dynamodb.AttributeValue{NS: ns}, nil
+	case *types.AttributeValueMemberBS:
+		bs := av.Value
+		if bs == nil {
+			bs = [][]byte{}
+		}
+		return &dynamodb.AttributeValue{BS: bs}, nil
+	case *types.AttributeValueMemberL:
+		l := make([]*dynamodb.AttributeValue, len(av.Value))
+		for i, elem := range av.Value {
+			v1, err := ToV1(elem)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = v1
+		}
+		return &dynamodb.AttributeValue{L: l}, nil
+	case *types.AttributeValueMemberM:
+		m, err := ToV1Item(av.Value)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			m = map[string]*dynamodb.AttributeValue{}
+		}
+		return &dynamodb.AttributeValue{M: m}, nil
+	}
+
+	return nil, fmt.Errorf("ddbv2: unsupported attribute value %T", av)
+}
+
+// ToV1Item converts an aws-sdk-go-v2 item to an aws-sdk-go item.
+func ToV1Item(item map[string]types.AttributeValue) (map[string]*dynamodb.AttributeValue, error) {
+	if item == nil {
+		return nil, nil
+	}
+
+	m := make(map[string]*dynamodb.AttributeValue, len(item))
+	for k, av := range item {
+		v1, err := ToV1(av)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v1
+	}
+	return m, nil
+}
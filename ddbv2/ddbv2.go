@@ -0,0 +1,59 @@
+// Package ddbv2 marshals Go values to the attribute values of aws-sdk-go-v2
+// and back. It follows package ddb in every rule, including struct tags,
+// options, sets, times and the Marshaler and Unmarshaler interfaces, so a
+// value encodes to the same attributes with either SDK.
+package ddbv2
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	ddb "github.com/runtakun/dynamodb-marshaler-go"
+)
+
+// Marshal converts map or struct to dynamodb attribute values like
+// ddb.Marshal. It returns nil if iv is neither a map nor a pointer to a
+// struct, leaves out values of unsupported types, and panics if a Marshaler
+// fails.
+func Marshal(iv interface{}, opts ...ddb.EncodeOption) map[string]types.AttributeValue {
+	return FromV1Item(ddb.Marshal(iv, opts...))
+}
+
+// MarshalE converts map or struct to dynamodb attribute values like
+// ddb.MarshalE, reporting unsupported values as an error.
+func MarshalE(iv interface{}, opts ...ddb.EncodeOption) (map[string]types.AttributeValue, error) {
+	item, err := ddb.MarshalE(iv, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return FromV1Item(item), nil
+}
+
+// MarshalAttributeValue converts any value to a single dynamodb attribute
+// value like ddb.MarshalAttributeValue.
+func MarshalAttributeValue(iv interface{}, opts ...ddb.EncodeOption) (types.AttributeValue, error) {
+	av, err := ddb.MarshalAttributeValue(iv, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return FromV1(av), nil
+}
+
+// Unmarshal converts dynamodb attribute values to map or struct like
+// ddb.Unmarshal.
+func Unmarshal(item map[string]types.AttributeValue, v interface{}, opts ...ddb.DecodeOption) error {
+	v1, err := ToV1Item(item)
+	if err != nil {
+		return err
+	}
+	return ddb.Unmarshal(v1, v, opts...)
+}
+
+// UnmarshalAttributeValue converts a single dynamodb attribute value to the
+// value v points to like ddb.UnmarshalAttributeValue.
+func UnmarshalAttributeValue(av types.AttributeValue, v interface{}, opts ...ddb.DecodeOption) error {
+	v1, err := ToV1(av)
+	if err != nil {
+		return err
+	}
+	return ddb.UnmarshalAttributeValue(v1, v, opts...)
+}
@@ -0,0 +1,13 @@
+package ddbv2_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDdbv2(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ddbv2 Suite")
+}
@@ -0,0 +1,167 @@
+package ddbv2_test
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	ddb "github.com/runtakun/dynamodb-marshaler-go"
+	. "github.com/runtakun/dynamodb-marshaler-go/ddbv2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type address struct {
+	City string `dynamodb:"city"`
+}
+
+type order struct {
+	ID        string            `dynamodb:"id"`
+	Total     float64           `dynamodb:"total"`
+	Paid      bool              `dynamodb:"paid"`
+	Note      string            `dynamodb:"note"`
+	Receipt   []byte            `dynamodb:"receipt"`
+	Tags      []string          `dynamodb:"tags,stringset"`
+	Scores    map[int]struct{}  `dynamodb:"scores"`
+	Lines     []string          `dynamodb:"lines"`
+	Shipping  *address          `dynamodb:"shipping"`
+	Extra     map[string]string `dynamodb:"extra,omitempty"`
+	ExpiresAt time.Time         `dynamodb:"expires_at,unixtime"`
+}
+
+var _ = Describe("Ddbv2", func() {
+
+	sample := func() *order {
+		return &order{
+			ID:        "o-1",
+			Total:     12.5,
+			Paid:      true,
+			Receipt:   []byte{0x1, 0x2},
+			Tags:      []string{"a", "b"},
+			Scores:    map[int]struct{}{3: {}, 1: {}},
+			Lines:     []string{"x", ""},
+			Shipping:  &address{City: "Tokyo"},
+			ExpiresAt: time.Unix(1500000000, 0),
+		}
+	}
+
+	Context("Marshal", func() {
+		It("should produce v2 members", func() {
+			sut := Marshal(sample())
+
+			Expect(sut["id"]).To(Equal(&types.AttributeValueMemberS{Value: "o-1"}))
+			Expect(sut["total"]).To(Equal(&types.AttributeValueMemberN{Value: "12.5"}))
+			Expect(sut["paid"]).To(Equal(&types.AttributeValueMemberBOOL{Value: true}))
+			Expect(sut["note"]).To(Equal(&types.AttributeValueMemberNULL{Value: true}))
+			Expect(sut["receipt"]).To(Equal(&types.AttributeValueMemberB{Value: []byte{0x1, 0x2}}))
+			Expect(sut["tags"]).To(Equal(&types.AttributeValueMemberSS{Value: []string{"a", "b"}}))
+			Expect(sut["scores"]).To(Equal(&types.AttributeValueMemberNS{Value: []string{"1", "3"}}))
+			Expect(sut["lines"]).To(Equal(&types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberS{Value: "x"},
+				&types.AttributeValueMemberNULL{Value: true},
+			}}))
+			Expect(sut["shipping"]).To(Equal(&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"city": &types.AttributeValueMemberS{Value: "Tokyo"},
+			}}))
+			Expect(sut).NotTo(HaveKey("extra"))
+			Expect(sut["expires_at"]).To(Equal(&types.AttributeValueMemberN{Value: "1500000000"}))
+		})
+
+		It("should encode like package ddb", func() {
+			v1 := ddb.Marshal(sample())
+			sut, err := ToV1Item(Marshal(sample()))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut).To(Equal(v1))
+		})
+
+		It("should apply options", func() {
+			sut := Marshal(&struct {
+				Name string `json:"name" dynamodbav:"n"`
+			}{Name: "foo"}, ddb.TagNames("dynamodbav"))
+			Expect(sut).To(HaveKey("n"))
+		})
+
+		It("should return nil for invalid value", func() {
+			Expect(Marshal("foo")).To(BeNil())
+		})
+
+		It("should report unsupported value", func() {
+			_, err := MarshalE(&struct{ C chan int }{C: make(chan int)})
+			Expect(err).To(BeAssignableToTypeOf(&ddb.UnsupportedTypeError{}))
+		})
+
+		It("should marshal a single value", func() {
+			sut, err := MarshalAttributeValue([]int{1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut).To(Equal(&types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberN{Value: "1"},
+			}}))
+		})
+	})
+
+	Context("Unmarshal", func() {
+		It("should restore marshaled value", func() {
+			var sut order
+			Expect(Unmarshal(Marshal(sample()), &sut)).To(Succeed())
+
+			want := sample()
+			Expect(sut.ID).To(Equal(want.ID))
+			Expect(sut.Total).To(Equal(want.Total))
+			Expect(sut.Paid).To(BeTrue())
+			Expect(sut.Receipt).To(Equal(want.Receipt))
+			Expect(sut.Tags).To(Equal(want.Tags))
+			Expect(sut.Scores).To(Equal(want.Scores))
+			Expect(sut.Lines).To(Equal(want.Lines))
+			Expect(sut.Shipping).To(Equal(want.Shipping))
+			Expect(sut.ExpiresAt.Equal(want.ExpiresAt)).To(BeTrue())
+		})
+
+		It("should apply options", func() {
+			var sut struct {
+				Count int `dynamodb:"count"`
+			}
+			err := Unmarshal(map[string]types.AttributeValue{
+				"count": &types.AttributeValueMemberS{Value: "1"},
+			}, &sut, ddb.Strict())
+			Expect(err).To(BeAssignableToTypeOf(&ddb.UnmarshalTypeError{}))
+		})
+
+		It("should unmarshal a single value", func() {
+			var sut []string
+			Expect(UnmarshalAttributeValue(&types.AttributeValueMemberSS{Value: []string{"a"}}, &sut)).To(Succeed())
+			Expect(sut).To(Equal([]string{"a"}))
+		})
+
+		It("should report unknown member", func() {
+			var sut map[string]interface{}
+			err := Unmarshal(map[string]types.AttributeValue{
+				"foo": &types.UnknownUnionMember{Tag: "X"},
+			}, &sut)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("conversion", func() {
+		It("should keep empty members typed", func() {
+			sut, err := ToV1(&types.AttributeValueMemberL{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut.L).NotTo(BeNil())
+
+			sut, err = ToV1(&types.AttributeValueMemberM{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut.M).NotTo(BeNil())
+
+			sut, err = ToV1(&types.AttributeValueMemberB{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut.B).NotTo(BeNil())
+		})
+
+		It("should convert v1 values", func() {
+			Expect(FromV1(&dynamodb.AttributeValue{BS: [][]byte{{0x1}}})).To(Equal(&types.AttributeValueMemberBS{Value: [][]byte{{0x1}}}))
+			Expect(FromV1(&dynamodb.AttributeValue{NULL: aws.Bool(true)})).To(Equal(&types.AttributeValueMemberNULL{Value: true}))
+			Expect(FromV1(&dynamodb.AttributeValue{})).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,244 @@
+package ddb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// A DynamoJSONError describes a value which is not a well-formed attribute
+// value of the DynamoDB JSON format.
+type DynamoJSONError struct {
+	Path string
+	Msg  string
+}
+
+func (e *DynamoJSONError) Error() string {
+	return "ddb: invalid DynamoDB JSON at " + e.Path + ": " + e.Msg
+}
+
+// MarshalDynamoJSON converts map or struct to the DynamoDB JSON format used
+// by the AWS CLI, table exports and stream records, such as
+// {"name":{"S":"x"},"n":{"N":"1"}}. Values are marshaled like MarshalE; an
+// item of type map[string]*dynamodb.AttributeValue is written as is.
+// Numbers keep their text and binaries are written in base64.
+func MarshalDynamoJSON(iv interface{}, opts ...EncodeOption) ([]byte, error) {
+	item, ok := iv.(map[string]*dynamodb.AttributeValue)
+	if !ok {
+		var err error
+		item, err = MarshalE(iv, opts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := itemToDynamoJSONValue(item, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(m); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func itemToDynamoJSONValue(item map[string]*dynamodb.AttributeValue, path string) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(item))
+	for k, av := range item {
+		v, err := attrValueToDynamoJSONValue(av, joinPath(path, k))
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+func attrValueToDynamoJSONValue(value *dynamodb.AttributeValue, path string) (interface{}, error) {
+	if value == nil {
+		return nil, &DynamoJSONError{Path: path, Msg: "nil attribute value"}
+	}
+
+	switch {
+	case value.S != nil:
+		return map[string]interface{}{"S": *value.S}, nil
+	case value.N != nil:
+		return map[string]interface{}{"N": *value.N}, nil
+	case value.B != nil:
+		return map[string]interface{}{"B": value.B}, nil
+	case value.BOOL != nil:
+		return map[string]interface{}{"BOOL": *value.BOOL}, nil
+	case value.NULL != nil:
+		return map[string]interface{}{"NULL": *value.NULL}, nil
+	case value.SS != nil:
+		return map[string]interface{}{"SS": aws.StringValueSlice(value.SS)}, nil
+	case value.NS != nil:
+		return map[string]interface{}{"NS": aws.StringValueSlice(value.NS)}, nil
+	case value.BS != nil:
+		return map[string]interface{}{"BS": value.BS}, nil
+	case value.L != nil:
+		list := make([]interface{}, len(value.L))
+		for i, l := range value.L {
+			v, err := attrValueToDynamoJSONValue(l, indexPath(path, i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return map[string]interface{}{"L": list}, nil
+	case value.M != nil:
+		m, err := itemToDynamoJSONValue(value.M, path)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"M": m}, nil
+	}
+
+	return nil, &DynamoJSONError{Path: path, Msg: "attribute value has no member set"}
+}
+
+// UnmarshalDynamoJSON converts an item in the DynamoDB JSON format to map or
+// struct like Unmarshal. If v is a *map[string]*dynamodb.AttributeValue the
+// item is stored in it as is. N accepts a JSON string or number and keeps
+// its text exactly; B and BS members are decoded from base64.
+func UnmarshalDynamoJSON(data []byte, v interface{}, opts ...DecodeOption) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	item, err := dynamoJSONToItem(raw, "")
+	if err != nil {
+		return err
+	}
+
+	if p, ok := v.(*map[string]*dynamodb.AttributeValue); ok {
+		*p = item
+		return nil
+	}
+
+	return Unmarshal(item, v, opts...)
+}
+
+func dynamoJSONToItem(raw map[string]json.RawMessage, path string) (map[string]*dynamodb.AttributeValue, error) {
+	item := make(map[string]*dynamodb.AttributeValue, len(raw))
+	for k, r := range raw {
+		av, err := dynamoJSONToAttrValue(r, joinPath(path, k))
+		if err != nil {
+			return nil, err
+		}
+		item[k] = av
+	}
+	return item, nil
+}
+
+func dynamoJSONToAttrValue(data json.RawMessage, path string) (*dynamodb.AttributeValue, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil || members == nil {
+		return nil, &DynamoJSONError{Path: path, Msg: "attribute value must be an object"}
+	}
+	if len(members) != 1 {
+		return nil, &DynamoJSONError{Path: path, Msg: "attribute value must have exactly one member"}
+	}
+
+	var name string
+	var raw json.RawMessage
+	for name, raw = range members {
+	}
+
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil, &DynamoJSONError{Path: path, Msg: name + " must not be null"}
+	}
+
+	av := &dynamodb.AttributeValue{}
+	var err error
+	switch name {
+	case "S":
+		err = json.Unmarshal(raw, &av.S)
+	case "N":
+		av.N, err = dynamoJSONNumber(raw)
+	case "B":
+		err = json.Unmarshal(raw, &av.B)
+	case "BOOL":
+		err = json.Unmarshal(raw, &av.BOOL)
+	case "NULL":
+		err = json.Unmarshal(raw, &av.NULL)
+	case "SS":
+		var list []string
+		if err = json.Unmarshal(raw, &list); err == nil {
+			av.SS = aws.StringSlice(list)
+		}
+	case "NS":
+		var list []json.RawMessage
+		if err = json.Unmarshal(raw, &list); err == nil {
+			av.NS = make([]*string, len(list))
+			for i, r := range list {
+				if av.NS[i], err = dynamoJSONNumber(r); err != nil {
+					break
+				}
+			}
+		}
+	case "BS":
+		var list []string
+		if err = json.Unmarshal(raw, &list); err == nil {
+			av.BS = make([][]byte, len(list))
+			for i, s := range list {
+				if av.BS[i], err = base64.StdEncoding.DecodeString(s); err != nil {
+					break
+				}
+			}
+		}
+	case "L":
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, &DynamoJSONError{Path: path, Msg: "L must be an array"}
+		}
+		av.L = make([]*dynamodb.AttributeValue, len(list))
+		for i, r := range list {
+			if av.L[i], err = dynamoJSONToAttrValue(r, indexPath(path, i)); err != nil {
+				return nil, err
+			}
+		}
+		return av, nil
+	case "M":
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return nil, &DynamoJSONError{Path: path, Msg: "M must be an object"}
+		}
+		if av.M, err = dynamoJSONToItem(m, path); err != nil {
+			return nil, err
+		}
+		return av, nil
+	default:
+		return nil, &DynamoJSONError{Path: path, Msg: "unknown member " + strconv.Quote(name)}
+	}
+	if err != nil {
+		return nil, &DynamoJSONError{Path: path, Msg: "invalid " + name + " member: " + err.Error()}
+	}
+	return av, nil
+}
+
+// dynamoJSONNumber returns the text of a number written as a JSON string,
+// as the DynamoDB JSON format does, or as a JSON number.
+func dynamoJSONNumber(raw json.RawMessage) (*string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return &s, nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return nil, err
+	}
+	s = n.String()
+	return &s, nil
+}
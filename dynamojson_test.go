@@ -0,0 +1,167 @@
+package ddb_test
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	. "github.com/runtakun/dynamodb-marshaler-go"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type invoice struct {
+	ID      string            `dynamodb:"id"`
+	Total   float64           `dynamodb:"total"`
+	Paid    bool              `dynamodb:"paid"`
+	Note    *string           `dynamodb:"note"`
+	Scan    []byte            `dynamodb:"scan"`
+	Pages   [][]byte          `dynamodb:"pages,binaryset"`
+	Tags    []string          `dynamodb:"tags,stringset"`
+	Lines   []interface{}     `dynamodb:"lines"`
+	Address map[string]string `dynamodb:"address"`
+}
+
+var _ = Describe("DynamoDB JSON", func() {
+
+	Context("MarshalDynamoJSON", func() {
+		It("should write typed attribute values", func() {
+			b, err := MarshalDynamoJSON(&struct {
+				Name  string   `dynamodb:"name"`
+				N     int      `dynamodb:"n"`
+				Note  *string  `dynamodb:"note"`
+				Blob  []byte   `dynamodb:"blob"`
+				Pages [][]byte `dynamodb:"pages,binaryset"`
+				Tags  []string `dynamodb:"tags,stringset"`
+				Lines []int    `dynamodb:"lines"`
+				Sub   child    `dynamodb:"sub"`
+				HTML  string   `dynamodb:"html"`
+			}{
+				Name:  "x",
+				N:     1,
+				Blob:  []byte("hi"),
+				Pages: [][]byte{[]byte("a")},
+				Tags:  []string{"b", "a"},
+				Lines: []int{},
+				Sub:   child{Content: "c"},
+				HTML:  "<a&b>",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(`{"blob":{"B":"aGk="},"html":{"S":"<a&b>"},"lines":{"L":[]},"n":{"N":"1"},"name":{"S":"x"},"note":{"NULL":true},"pages":{"BS":["YQ=="]},"sub":{"M":{"content":{"S":"c"}}},"tags":{"SS":["b","a"]}}`))
+		})
+
+		It("should keep number text exactly", func() {
+			b, err := MarshalDynamoJSON(map[string]*dynamodb.AttributeValue{
+				"n":  {N: aws.String("12345678901234567890.000000001")},
+				"ns": {NS: []*string{aws.String("1e400")}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(`{"n":{"N":"12345678901234567890.000000001"},"ns":{"NS":["1e400"]}}`))
+		})
+
+		It("should report attribute value without member", func() {
+			_, err := MarshalDynamoJSON(map[string]*dynamodb.AttributeValue{
+				"m": {M: map[string]*dynamodb.AttributeValue{"x": {}}},
+			})
+			Expect(err).To(BeAssignableToTypeOf(&DynamoJSONError{}))
+			Expect(err.(*DynamoJSONError).Path).To(Equal("m.x"))
+		})
+
+		It("should report unsupported value", func() {
+			_, err := MarshalDynamoJSON(&struct{ C chan int }{})
+			Expect(err).To(BeAssignableToTypeOf(&UnsupportedTypeError{}))
+		})
+	})
+
+	Context("UnmarshalDynamoJSON", func() {
+		const data = `{
+			"id": {"S": "inv-1"},
+			"total": {"N": "12345678901234567890.000000001"},
+			"paid": {"BOOL": true},
+			"note": {"NULL": true},
+			"scan": {"B": "aGk="},
+			"pages": {"BS": ["YQ==", "Yg=="]},
+			"tags": {"SS": ["a", "b"]},
+			"lines": {"L": [{"S": "x"}, {"N": 2}]},
+			"address": {"M": {"city": {"S": "Tokyo"}}}
+		}`
+
+		It("should read typed attribute values", func() {
+			var sut invoice
+			Expect(UnmarshalDynamoJSON([]byte(data), &sut)).To(Succeed())
+
+			Expect(sut.ID).To(Equal("inv-1"))
+			Expect(sut.Total).To(Equal(12345678901234567890.000000001))
+			Expect(sut.Paid).To(BeTrue())
+			Expect(sut.Note).To(BeNil())
+			Expect(sut.Scan).To(Equal([]byte("hi")))
+			Expect(sut.Pages).To(Equal([][]byte{[]byte("a"), []byte("b")}))
+			Expect(sut.Tags).To(Equal([]string{"a", "b"}))
+			Expect(sut.Lines).To(Equal([]interface{}{"x", float64(2)}))
+			Expect(sut.Address).To(Equal(map[string]string{"city": "Tokyo"}))
+		})
+
+		It("should store attribute values as is", func() {
+			var sut map[string]*dynamodb.AttributeValue
+			Expect(UnmarshalDynamoJSON([]byte(data), &sut)).To(Succeed())
+
+			Expect(*sut["total"].N).To(Equal("12345678901234567890.000000001"))
+			Expect(*sut["lines"].L[1].N).To(Equal("2"))
+			Expect(sut["pages"].BS).To(Equal([][]byte{[]byte("a"), []byte("b")}))
+		})
+
+		It("should round trip", func() {
+			var item map[string]*dynamodb.AttributeValue
+			Expect(UnmarshalDynamoJSON([]byte(data), &item)).To(Succeed())
+
+			b, err := MarshalDynamoJSON(item)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b).To(MatchJSON(`{
+				"id": {"S": "inv-1"},
+				"total": {"N": "12345678901234567890.000000001"},
+				"paid": {"BOOL": true},
+				"note": {"NULL": true},
+				"scan": {"B": "aGk="},
+				"pages": {"BS": ["YQ==", "Yg=="]},
+				"tags": {"SS": ["a", "b"]},
+				"lines": {"L": [{"S": "x"}, {"N": "2"}]},
+				"address": {"M": {"city": {"S": "Tokyo"}}}
+			}`))
+		})
+
+		It("should apply options", func() {
+			var sut invoice
+			err := UnmarshalDynamoJSON([]byte(`{"id": {"N": "1"}}`), &sut, Strict())
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+		})
+
+		It("should report malformed attribute values", func() {
+			for _, data := range []string{
+				`{"a": "x"}`,
+				`{"a": {}}`,
+				`{"a": {"S": "x", "N": "1"}}`,
+				`{"a": {"X": "x"}}`,
+				`{"a": {"S": null}}`,
+				`{"a": {"S": 1}}`,
+				`{"a": {"B": "!"}}`,
+				`{"a": {"BS": ["!"]}}`,
+				`{"a": {"N": true}}`,
+				`{"a": {"M": {"b": {"L": [{"Q": 1}]}}}}`,
+			} {
+				var sut map[string]*dynamodb.AttributeValue
+				err := UnmarshalDynamoJSON([]byte(data), &sut)
+				Expect(err).To(BeAssignableToTypeOf(&DynamoJSONError{}), data)
+			}
+		})
+
+		It("should report path of malformed attribute value", func() {
+			var sut map[string]*dynamodb.AttributeValue
+			err := UnmarshalDynamoJSON([]byte(`{"a": {"M": {"b": {"L": [{"S": "x"}, {"Q": 1}]}}}}`), &sut)
+			Expect(err.(*DynamoJSONError).Path).To(Equal("a.b[1]"))
+		})
+
+		It("should report invalid JSON", func() {
+			var sut invoice
+			Expect(UnmarshalDynamoJSON([]byte(`{`), &sut)).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,241 @@
+package ddb
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// A field describes a struct field which is marshaled to an attribute.
+type field struct {
+	name   string
+	tagged bool
+	index  []int
+	typ    reflect.Type
+
+	omitEmpty  bool
+	setType    string
+	timeFormat string
+}
+
+type fieldCacheKey struct {
+	t    reflect.Type
+	tags string
+}
+
+var fieldCache struct {
+	sync.RWMutex
+	m map[fieldCacheKey][]field
+}
+
+// cachedTypeFields is like typeFields but computes the fields of each type
+// only once for each set of tag names. It is safe for concurrent use.
+func cachedTypeFields(t reflect.Type, tags *tagSet) []field {
+	key := fieldCacheKey{t: t, tags: tags.key}
+
+	fieldCache.RLock()
+	fields, ok := fieldCache.m[key]
+	fieldCache.RUnlock()
+	if ok {
+		return fields
+	}
+
+	fields = typeFields(t, tags.names)
+
+	fieldCache.Lock()
+	if fieldCache.m == nil {
+		fieldCache.m = make(map[fieldCacheKey][]field)
+	}
+	fieldCache.m[key] = fields
+	fieldCache.Unlock()
+
+	return fields
+}
+
+// typeFields returns the fields of struct type t which are marshaled to
+// attributes, in index order. Fields of embedded structs other than
+// time.Time are promoted into t unless the embedded field has a name in its
+// tag. Promotion follows Go's visibility rules: among fields with the same
+// attribute name the shallowest one wins, a tagged field wins over untagged
+// ones at the same depth, and otherwise all of them are dropped.
+func typeFields(t reflect.Type, tagNames []string) []field {
+	var current []field
+	next := []field{{typ: t}}
+
+	var count, nextCount map[reflect.Type]int
+	visited := map[reflect.Type]bool{}
+
+	var fields []field
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+			visited[f.typ] = true
+
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if sf.PkgPath != "" && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if sf.PkgPath != "" {
+					continue
+				}
+
+				name, opts := parseTag(lookupTag(sf, tagNames))
+				if name == "-" {
+					continue
+				}
+
+				index := make([]int, len(f.index)+1)
+				copy(index, f.index)
+				index[len(f.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+
+				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct || ft == timeType {
+					tagged := name != ""
+					if name == "" {
+						name = sf.Name
+					}
+					fields = append(fields, field{
+						name:       name,
+						tagged:     tagged,
+						index:      index,
+						typ:        sf.Type,
+						omitEmpty:  opts.omitEmpty(),
+						setType:    opts.setType(),
+						timeFormat: opts.timeFormat(),
+					})
+					if count[f.typ] > 1 {
+						// The struct is embedded more than once at this depth, so
+						// its fields annihilate each other. One duplicate is
+						// enough for dominantField to drop them.
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, field{name: ft.Name(), index: index, typ: ft})
+				}
+			}
+		}
+	}
+
+	sort.Sort(byName(fields))
+
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		name := fields[i].name
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].name != name {
+				break
+			}
+		}
+		if advance == 1 {
+			out = append(out, fields[i])
+			continue
+		}
+		if dominant, ok := dominantField(fields[i : i+advance]); ok {
+			out = append(out, dominant)
+		}
+	}
+
+	fields = out
+	sort.Sort(byIndex(fields))
+
+	return fields
+}
+
+// dominantField returns the field which hides the others with the same
+// name. fields is sorted by depth and tagged fields come first at a depth.
+func dominantField(fields []field) (field, bool) {
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tagged == fields[1].tagged {
+		return field{}, false
+	}
+	return fields[0], true
+}
+
+// byName sorts fields by name, breaking ties with depth, then tagged
+// before untagged, then index sequence.
+type byName []field
+
+func (x byName) Len() int      { return len(x) }
+func (x byName) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x byName) Less(i, j int) bool {
+	if x[i].name != x[j].name {
+		return x[i].name < x[j].name
+	}
+	if len(x[i].index) != len(x[j].index) {
+		return len(x[i].index) < len(x[j].index)
+	}
+	if x[i].tagged != x[j].tagged {
+		return x[i].tagged
+	}
+	return byIndex(x).Less(i, j)
+}
+
+// byIndex sorts fields by index sequence.
+type byIndex []field
+
+func (x byIndex) Len() int      { return len(x) }
+func (x byIndex) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x byIndex) Less(i, j int) bool {
+	for k, xik := range x[i].index {
+		if k >= len(x[j].index) {
+			return false
+		}
+		if xik != x[j].index[k] {
+			return xik < x[j].index[k]
+		}
+	}
+	return len(x[i].index) < len(x[j].index)
+}
+
+// fieldByIndex returns the field of struct v at index. It reports false if
+// an embedded struct pointer on the way is nil.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// fieldByIndexAlloc returns the field of struct v at index, allocating nil
+// embedded struct pointers on the way. It reports false if such a pointer
+// cannot be set because its struct type is unexported.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
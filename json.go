@@ -0,0 +1,135 @@
+package ddb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// FromJSON converts a JSON object to an item without going through a Go
+// type. Objects become M, arrays L, strings S, empty ones included, numbers
+// N with their literal text, booleans BOOL and null NULL.
+func FromJSON(data []byte) (map[string]*dynamodb.AttributeValue, error) {
+	av, err := jsonToAttrValue(data, EmptyLiteral)
+	if err != nil {
+		return nil, err
+	}
+	if av == nil || av.M == nil {
+		return nil, errors.New("ddb: top-level JSON value must be an object")
+	}
+
+	return av.M, nil
+}
+
+// ToJSON converts an item to a JSON object, the reverse of FromJSON. Numbers
+// keep their literal text, SS and NS are written as arrays of strings and
+// numbers, B as a base64 string and BS as an array of base64 strings.
+// Attribute values with no member set are written as null.
+func ToJSON(item map[string]*dynamodb.AttributeValue) ([]byte, error) {
+	if item == nil {
+		item = map[string]*dynamodb.AttributeValue{}
+	}
+	return attrValueToJSON(&dynamodb.AttributeValue{M: item})
+}
+
+// jsonToAttrValue converts a JSON text to an attribute value. Objects become
+// M, arrays L, strings S, numbers N with their literal text, booleans BOOL
+// and null NULL. Empty strings are encoded as set by mode; nil means an
+// empty string to be left out.
+func jsonToAttrValue(b []byte, mode EmptyMode) (*dynamodb.AttributeValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("ddb: invalid character after top-level JSON value")
+	}
+
+	return jsonValueToAttrValue(v, mode), nil
+}
+
+func jsonValueToAttrValue(v interface{}, mode EmptyMode) *dynamodb.AttributeValue {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]*dynamodb.AttributeValue, len(v))
+		for k, mv := range v {
+			if av := jsonValueToAttrValue(mv, mode); av != nil {
+				m[k] = av
+			}
+		}
+		return &dynamodb.AttributeValue{M: m}
+	case []interface{}:
+		list := make([]*dynamodb.AttributeValue, len(v))
+		for i, lv := range v {
+			list[i] = jsonValueToAttrValue(lv, mode)
+			if list[i] == nil {
+				list[i] = makeNullAttrValue()
+			}
+		}
+		return &dynamodb.AttributeValue{L: list}
+	case string:
+		if v == "" {
+			return marshalEmpty(mode, makeStringAttrValue(""))
+		}
+		return makeStringAttrValue(v)
+	case json.Number:
+		return makeNumberAttrValue(v.String())
+	case bool:
+		return &dynamodb.AttributeValue{BOOL: aws.Bool(v)}
+	}
+
+	return makeNullAttrValue()
+}
+
+// attrValueToJSON converts an attribute value to a JSON text, the reverse of
+// jsonToAttrValue. Numbers keep their literal text, binaries are written as
+// base64 strings and sets as arrays.
+func attrValueToJSON(value *dynamodb.AttributeValue) ([]byte, error) {
+	return json.Marshal(attrValueToJSONValue(value))
+}
+
+func attrValueToJSONValue(value *dynamodb.AttributeValue) interface{} {
+	switch {
+	case value == nil:
+		return nil
+	case value.S != nil:
+		return *value.S
+	case value.N != nil:
+		return json.Number(*value.N)
+	case value.BOOL != nil:
+		return *value.BOOL
+	case value.B != nil:
+		return value.B
+	case value.SS != nil:
+		return aws.StringValueSlice(value.SS)
+	case value.NS != nil:
+		arr := make([]json.Number, len(value.NS))
+		for i, n := range value.NS {
+			arr[i] = json.Number(*n)
+		}
+		return arr
+	case value.BS != nil:
+		return value.BS
+	case value.L != nil:
+		arr := make([]interface{}, len(value.L))
+		for i, l := range value.L {
+			arr[i] = attrValueToJSONValue(l)
+		}
+		return arr
+	case value.M != nil:
+		m := make(map[string]interface{}, len(value.M))
+		for k, mv := range value.M {
+			m[k] = attrValueToJSONValue(mv)
+		}
+		return m
+	}
+
+	return nil
+}
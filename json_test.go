@@ -0,0 +1,115 @@
+package ddb_test
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	. "github.com/runtakun/dynamodb-marshaler-go"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON", func() {
+
+	Context("FromJSON", func() {
+		It("should convert a JSON object", func() {
+			item, err := FromJSON([]byte(`{
+				"s": "foo",
+				"empty": "",
+				"n": 12345678901234567890.000000001,
+				"t": true,
+				"null": null,
+				"l": [1, "a", []],
+				"m": {"k": {}}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(item).To(Equal(map[string]*dynamodb.AttributeValue{
+				"s":     {S: aws.String("foo")},
+				"empty": {S: aws.String("")},
+				"n":     {N: aws.String("12345678901234567890.000000001")},
+				"t":     {BOOL: aws.Bool(true)},
+				"null":  {NULL: aws.Bool(true)},
+				"l": {L: []*dynamodb.AttributeValue{
+					{N: aws.String("1")},
+					{S: aws.String("a")},
+					{L: []*dynamodb.AttributeValue{}},
+				}},
+				"m": {M: map[string]*dynamodb.AttributeValue{
+					"k": {M: map[string]*dynamodb.AttributeValue{}},
+				}},
+			}))
+		})
+
+		It("should reject other top-level values", func() {
+			for _, data := range []string{`[]`, `"a"`, `1`, `null`} {
+				_, err := FromJSON([]byte(data))
+				Expect(err).To(HaveOccurred(), data)
+			}
+		})
+
+		It("should reject invalid JSON", func() {
+			for _, data := range []string{`{`, `{} {}`, ``} {
+				_, err := FromJSON([]byte(data))
+				Expect(err).To(HaveOccurred(), data)
+			}
+		})
+	})
+
+	Context("ToJSON", func() {
+		It("should convert an item", func() {
+			b, err := ToJSON(map[string]*dynamodb.AttributeValue{
+				"s":    {S: aws.String("foo")},
+				"n":    {N: aws.String("12345678901234567890.000000001")},
+				"t":    {BOOL: aws.Bool(false)},
+				"null": {NULL: aws.Bool(true)},
+				"none": {},
+				"nil":  nil,
+				"b":    {B: []byte("hi")},
+				"ss":   {SS: aws.StringSlice([]string{"b", "a"})},
+				"ns":   {NS: aws.StringSlice([]string{"1", "2.5"})},
+				"bs":   {BS: [][]byte{[]byte("a"), []byte("b")}},
+				"l":    {L: []*dynamodb.AttributeValue{{N: aws.String("1")}}},
+				"m":    {M: map[string]*dynamodb.AttributeValue{"k": {S: aws.String("v")}}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(b).To(MatchJSON(`{
+				"s": "foo",
+				"n": 12345678901234567890.000000001,
+				"t": false,
+				"null": null,
+				"none": null,
+				"nil": null,
+				"b": "aGk=",
+				"ss": ["b", "a"],
+				"ns": [1, 2.5],
+				"bs": ["YQ==", "Yg=="],
+				"l": [1],
+				"m": {"k": "v"}
+			}`))
+			Expect(string(b)).To(ContainSubstring(`"n":12345678901234567890.000000001`))
+		})
+
+		It("should write an object for a nil item", func() {
+			b, err := ToJSON(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(`{}`))
+		})
+
+		It("should round trip a JSON document", func() {
+			const doc = `{"a":[1,{"b":null,"c":"d"}],"e":1e400,"f":false}`
+			item, err := FromJSON([]byte(doc))
+			Expect(err).NotTo(HaveOccurred())
+
+			b, err := ToJSON(item)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(doc))
+		})
+
+		It("should report invalid numbers", func() {
+			_, err := ToJSON(map[string]*dynamodb.AttributeValue{"n": {N: aws.String("x")}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -1,122 +1,520 @@
 package ddb
 
 import (
+	"encoding"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"sort"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
-var typeOfBytes = reflect.TypeOf([]byte(nil))
+var (
+	typeOfBytes = reflect.TypeOf([]byte(nil))
+	numberType  = reflect.TypeOf(json.Number(""))
+)
+
+// Marshaler is the interface implemented by types that can marshal
+// themselves into a dynamodb attribute value.
+type Marshaler interface {
+	MarshalDynamoDBAttributeValue() (*dynamodb.AttributeValue, error)
+}
+
+var (
+	marshalerType     = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// A MarshalerError is returned by MarshalE when a Marshaler, or an enabled
+// encoding.TextMarshaler or json.Marshaler, fails.
+type MarshalerError struct {
+	Path string
+	Type reflect.Type
+	Err  error
+}
 
-// Marshal converts map or struct to dynamodb attribute value
-func Marshal(iv interface{}) map[string]*dynamodb.AttributeValue {
-	kind := reflect.TypeOf(iv).Kind()
-	if kind == reflect.Map {
-		return marshalMap(reflect.ValueOf(iv))
-	} else if kind == reflect.Ptr {
-		return marshalStruct(reflect.ValueOf(iv).Elem())
+func (e *MarshalerError) Error() string {
+	if e.Path == "" {
+		return "ddb: error calling marshaler for type " + e.Type.String() + ": " + e.Err.Error()
 	}
+	return "ddb: error calling marshaler for type " + e.Type.String() + " at " + e.Path + ": " + e.Err.Error()
+}
 
-	return nil
+// An UnsupportedTypeError is returned by MarshalE when attempting to
+// marshal a value of a type which has no dynamodb representation.
+type UnsupportedTypeError struct {
+	Path string
+	Type reflect.Type
 }
 
-func marshalMap(value reflect.Value) map[string]*dynamodb.AttributeValue {
-	ret := make(map[string]*dynamodb.AttributeValue)
+func (e *UnsupportedTypeError) Error() string {
+	if e.Path == "" {
+		return "ddb: unsupported type: " + e.Type.String()
+	}
+	return "ddb: unsupported type: " + e.Type.String() + " at " + e.Path
+}
 
-	for _, keyValue := range value.MapKeys() {
-		if keyValue.Type().Kind() == reflect.String {
-			ret[keyValue.String()] = marshalValue(value.MapIndex(keyValue))
-		} else {
-			panic("map key must be string")
+// An InvalidNumberError describes a string to be written as a number which
+// is not one, such as a member of a numberset field.
+type InvalidNumberError struct {
+	Path   string // path to the attribute, e.g. "prices[3]"
+	Number string
+}
+
+func (e *InvalidNumberError) Error() string {
+	if e.Path == "" {
+		return "ddb: invalid number " + strconv.Quote(e.Number)
+	}
+	return "ddb: invalid number " + strconv.Quote(e.Number) + " at " + e.Path
+}
+
+// An InvalidMarshalError describes an invalid argument passed to MarshalE.
+// The argument must be a map or a struct, or a chain of non-nil pointers to
+// one, unless a Marshaler or TypeEncoder converts it to a M attribute value.
+type InvalidMarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidMarshalError) Error() string {
+	if e.Type == nil {
+		return "ddb: MarshalE(nil)"
+	}
+	if e.Type.Kind() == reflect.Ptr {
+		return "ddb: MarshalE(nil " + e.Type.String() + ")"
+	}
+	return "ddb: MarshalE(non-struct " + e.Type.String() + ")"
+}
+
+// An Encoder converts Go values to dynamodb attribute values following the
+// options it is created with. It is safe for concurrent use.
+type Encoder struct {
+	tags             *tagSet
+	useTextMarshaler bool
+	useJSONMarshaler bool
+	emptyStrings     EmptyMode
+	emptyBinaries    EmptyMode
+	emptySets        EmptyMode
+	typeEncoders     map[reflect.Type]EncodeFunc
+	skipUnsupported  bool
+}
+
+var defaultEncoder = NewEncoder()
+
+var errEmptySetLiteral = errors.New("ddb: EmptySets(EmptyLiteral): dynamodb does not accept empty sets")
+
+// NewEncoder returns an Encoder configured by opts.
+func NewEncoder(opts ...EncodeOption) *Encoder {
+	e := &Encoder{tags: defaultTagSet, emptyBinaries: EmptyLiteral}
+	for _, opt := range opts {
+		opt.applyEncode(e)
+	}
+	return e
+}
+
+// encoderFor returns the default Encoder, or a new one if opts are given.
+func encoderFor(opts []EncodeOption) *Encoder {
+	if len(opts) == 0 {
+		return defaultEncoder
+	}
+	return NewEncoder(opts...)
+}
+
+// Marshal converts map or struct to dynamodb attribute value. Structs may be
+// passed by value or through pointers, including pointers to pointers.
+// It returns nil if iv is neither a map nor a struct or is a nil pointer,
+// leaves out values of unsupported types such as funcs and channels, and
+// panics if a Marshaler fails or a numberset member is not a number. Use
+// MarshalE to get these failures as an error.
+func Marshal(iv interface{}, opts ...EncodeOption) map[string]*dynamodb.AttributeValue {
+	e := *encoderFor(opts)
+	e.skipUnsupported = true
+
+	item, err := e.Encode(iv)
+	if err != nil {
+		if _, ok := err.(*InvalidMarshalError); ok {
+			return nil
+		}
+		panic(err)
+	}
+
+	return item
+}
+
+// MarshalE converts map or struct to dynamodb attribute value like Marshal,
+// but reports unsupported values and Marshaler failures as an error.
+func MarshalE(iv interface{}, opts ...EncodeOption) (map[string]*dynamodb.AttributeValue, error) {
+	return encoderFor(opts).Encode(iv)
+}
+
+// Encode converts map or struct to dynamodb attribute value like MarshalE
+// with the options of e.
+func (e *Encoder) Encode(iv interface{}) (map[string]*dynamodb.AttributeValue, error) {
+	if e.emptySets == EmptyLiteral {
+		return nil, errEmptySetLiteral
+	}
+
+	value := reflect.ValueOf(iv)
+	if !value.IsValid() {
+		return nil, &InvalidMarshalError{}
+	}
+
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, &InvalidMarshalError{Type: reflect.TypeOf(iv)}
+		}
+		value = value.Elem()
+	}
+
+	if f, ok := e.typeEncoders[value.Type()]; ok {
+		av, err := f(value)
+		if err != nil {
+			return nil, &MarshalerError{Type: value.Type(), Err: err}
+		}
+		if av == nil || av.M == nil {
+			return nil, &InvalidMarshalError{Type: reflect.TypeOf(iv)}
+		}
+		return av.M, nil
+	}
+
+	if m, ok := implementer(value, marshalerType); ok {
+		av, err := m.(Marshaler).MarshalDynamoDBAttributeValue()
+		if err != nil {
+			return nil, &MarshalerError{Type: value.Type(), Err: err}
 		}
+		if av == nil || av.M == nil {
+			return nil, &InvalidMarshalError{Type: reflect.TypeOf(iv)}
+		}
+		return av.M, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		return e.marshalMap(value, "")
+	case reflect.Struct:
+		return e.marshalStruct(value, "")
 	}
 
-	return ret
+	return nil, &InvalidMarshalError{Type: reflect.TypeOf(iv)}
 }
 
-func marshalStruct(value reflect.Value) map[string]*dynamodb.AttributeValue {
-	t := value.Type()
+// MarshalAttributeValue converts any value to a single dynamodb attribute
+// value, following the same rules as fields marshaled by MarshalE. A nil
+// interface is marshaled as NULL.
+func MarshalAttributeValue(iv interface{}, opts ...EncodeOption) (*dynamodb.AttributeValue, error) {
+	return encoderFor(opts).EncodeAttributeValue(iv)
+}
 
-	numField := t.NumField()
+// EncodeAttributeValue converts any value to a single dynamodb attribute
+// value like MarshalAttributeValue with the options of e.
+func (e *Encoder) EncodeAttributeValue(iv interface{}) (*dynamodb.AttributeValue, error) {
+	if e.emptySets == EmptyLiteral {
+		return nil, errEmptySetLiteral
+	}
+
+	value := reflect.ValueOf(iv)
+	if !value.IsValid() {
+		return makeNullAttrValue(), nil
+	}
+
+	av, err := e.marshalValue(value, "")
+	if av == nil && err == nil {
+		return makeNullAttrValue(), nil
+	}
+	return av, err
+}
+
+func (e *Encoder) marshalMap(value reflect.Value, path string) (map[string]*dynamodb.AttributeValue, error) {
+	if value.Type().Key().Kind() != reflect.String {
+		return nil, e.unsupported(value.Type(), path)
+	}
 
 	ret := make(map[string]*dynamodb.AttributeValue)
-	for i := 0; i < numField; i++ {
-		f := t.Field(i)
 
-		if f.PkgPath != "" {
+	for _, keyValue := range value.MapKeys() {
+		key := keyValue.String()
+		av, err := e.marshalValue(value.MapIndex(keyValue), joinPath(path, key))
+		if err != nil {
+			return nil, err
+		}
+		if av == nil {
 			continue
 		}
+		ret[key] = av
+	}
 
-		name, option := parseTag(f.Tag.Get("json"))
-		if name == "-" {
+	return ret, nil
+}
+
+func (e *Encoder) marshalStruct(value reflect.Value, path string) (map[string]*dynamodb.AttributeValue, error) {
+	ret := make(map[string]*dynamodb.AttributeValue)
+	for _, f := range cachedTypeFields(value.Type(), e.tags) {
+		fv, ok := fieldByIndex(value, f.index)
+		if !ok {
 			continue
 		}
-		if option == "omitifempty" {
+		if f.omitEmpty && isEmptyValue(fv) {
 			continue
 		}
-		if name == "" {
-			name = f.Name
+
+		var av *dynamodb.AttributeValue
+		var err error
+		if f.setType != "" {
+			av, err = e.marshalSetValue(fv, f.setType, joinPath(path, f.name))
+		} else if tv, ok := timeValue(fv); ok && f.timeFormat != "" {
+			av = marshalTimeValue(tv, f.timeFormat)
+		} else {
+			av, err = e.marshalValue(fv, joinPath(path, f.name))
+		}
+		if err != nil {
+			return nil, err
 		}
-		ret[name] = marshalValue(value.FieldByIndex(f.Index))
+		if av == nil {
+			continue
+		}
+		ret[f.name] = av
 	}
 
-	return ret
+	return ret, nil
+}
+
+// joinPath appends an attribute name to path, e.g. "orders[3]" and "price"
+// become "orders[3].price".
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
 }
 
-func parseTag(tag string) (string, string) {
-	if idx := strings.Index(tag, ","); idx != -1 {
-		return tag[:idx], tag[idx+1:]
+// WithPath returns err with the attribute name prepended to the path it
+// carries, if err is one of the errors of this package that have a path.
+// Code converting the attributes of an item one at a time, such as the
+// methods generated by ddbgen, uses it to report the same paths as Marshal
+// and Unmarshal. Other errors are returned as they are.
+func WithPath(err error, name string) error {
+	switch e := err.(type) {
+	case *UnmarshalTypeError:
+		e.Path = prefixPath(name, e.Path)
+	case *UnmarshalNumberError:
+		e.Path = prefixPath(name, e.Path)
+	case *UnmarshalLengthError:
+		e.Path = prefixPath(name, e.Path)
+	case *UnmarshalTimeError:
+		e.Path = prefixPath(name, e.Path)
+	case *MarshalerError:
+		e.Path = prefixPath(name, e.Path)
+	case *UnsupportedTypeError:
+		e.Path = prefixPath(name, e.Path)
+	case *InvalidNumberError:
+		e.Path = prefixPath(name, e.Path)
 	}
-	return tag, ""
+	return err
 }
 
-func marshalValue(value reflect.Value) *dynamodb.AttributeValue {
+// prefixPath prepends an attribute name to path, e.g. "orders" and
+// "[3].price" become "orders[3].price".
+func prefixPath(name, path string) string {
+	if path == "" || path[0] == '[' {
+		return name + path
+	}
+	return name + "." + path
+}
+
+// indexPath appends a list index to path, e.g. "orders" and 3 become
+// "orders[3]".
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).IsZero()
+		}
+	}
+	return false
+}
+
+// marshalValue converts value to an attribute value. It returns nil for an
+// empty value to be left out of the enclosing item or map.
+func (e *Encoder) marshalValue(value reflect.Value, path string) (*dynamodb.AttributeValue, error) {
+	if f, ok := e.typeEncoders[value.Type()]; ok {
+		av, err := f(value)
+		if err != nil {
+			return nil, &MarshalerError{Path: path, Type: value.Type(), Err: err}
+		}
+		if av == nil {
+			return makeNullAttrValue(), nil
+		}
+		return av, nil
+	}
+
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return makeNullAttrValue(), nil
+	}
+
+	if av, ok, err := e.marshalCustomValue(value, path); ok {
+		return av, err
+	}
+
+	if value.Type() == timeType {
+		return marshalTimeValue(value, ""), nil
+	}
+
+	if value.Type() == numberType && value.Len() != 0 {
+		return makeNumberAttrValue(value.String()), nil
+	}
+
 	switch value.Type().Kind() {
 	case reflect.String:
-		return marshalStringValue(value)
+		return e.marshalString(value.String()), nil
 	case reflect.Bool:
-		return marshalBoolValue(value)
+		return marshalBoolValue(value), nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return marshalInt64Value(value)
+		return marshalInt64Value(value), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return marshalUint64Value(value)
+		return marshalUint64Value(value), nil
 	case reflect.Float32, reflect.Float64:
-		return marshalFloat64Value(value)
+		return marshalFloat64Value(value), nil
 	case reflect.Array:
-		return marshalArrayValue(value)
+		return e.marshalArrayValue(value, path)
 	case reflect.Interface:
-		return marshalInterfaceValue(value)
+		return e.marshalInterfaceValue(value, path)
 	case reflect.Map:
-		return marshalMapValue(value)
+		return e.marshalMapValue(value, path)
 	case reflect.Ptr:
-		return marshalPtrValue(value)
+		return e.marshalPtrValue(value, path)
 	case reflect.Slice:
 		if value.Type() == typeOfBytes {
-			return marshalBytesValue(value)
+			return e.marshalBytesValue(value), nil
 		}
-		return marshalSliceValue(value)
+		return e.marshalSliceValue(value, path)
 	case reflect.Struct:
-		return marshalStructValue(value)
-	case reflect.UnsafePointer:
+		return e.marshalStructValue(value, path)
+	}
+
+	return nil, e.unsupported(value.Type(), path)
+}
+
+// unsupported returns an *UnsupportedTypeError for t, or nil for Marshal,
+// which leaves values of unsupported types out.
+func (e *Encoder) unsupported(t reflect.Type, path string) error {
+	if e.skipUnsupported {
 		return nil
 	}
+	return &UnsupportedTypeError{Path: path, Type: t}
+}
+
+// marshalCustomValue marshals value by the Marshaler it implements or, if
+// enabled, by its encoding.TextMarshaler or json.Marshaler. It reports false
+// if value is to be marshaled by its kind.
+func (e *Encoder) marshalCustomValue(value reflect.Value, path string) (*dynamodb.AttributeValue, bool, error) {
+	if m, ok := implementer(value, marshalerType); ok {
+		av, err := m.(Marshaler).MarshalDynamoDBAttributeValue()
+		if err != nil {
+			return nil, true, &MarshalerError{Path: path, Type: value.Type(), Err: err}
+		}
+		if av == nil {
+			return makeNullAttrValue(), true, nil
+		}
+		return av, true, nil
+	}
+
+	if e.useTextMarshaler {
+		if m, ok := implementer(value, textMarshalerType); ok {
+			text, err := m.(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, true, &MarshalerError{Path: path, Type: value.Type(), Err: err}
+			}
+			return e.marshalString(string(text)), true, nil
+		}
+	}
+
+	if e.useJSONMarshaler {
+		if m, ok := implementer(value, jsonMarshalerType); ok {
+			b, err := m.(json.Marshaler).MarshalJSON()
+			if err != nil {
+				return nil, true, &MarshalerError{Path: path, Type: value.Type(), Err: err}
+			}
+			av, err := jsonToAttrValue(b, e.emptyStrings)
+			if err != nil {
+				return nil, true, &MarshalerError{Path: path, Type: value.Type(), Err: err}
+			}
+			return av, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
 
-	return nil
+// implementer returns value as the interface type iface if value or, when
+// value is addressable, a pointer to it implements iface.
+func implementer(value reflect.Value, iface reflect.Type) (interface{}, bool) {
+	if value.Kind() != reflect.Interface && value.Type().Implements(iface) {
+		return value.Interface(), true
+	}
+	if value.CanAddr() && reflect.PtrTo(value.Type()).Implements(iface) {
+		return value.Addr().Interface(), true
+	}
+	return nil, false
 }
 
-func marshalStringValue(value reflect.Value) *dynamodb.AttributeValue {
-	str := value.String()
+// marshalString converts str to a S, or an empty string as set by
+// EmptyStrings.
+func (e *Encoder) marshalString(str string) *dynamodb.AttributeValue {
 	if str == "" {
-		return makeNullAttrValue()
+		return marshalEmpty(e.emptyStrings, makeStringAttrValue(""))
 	}
 
 	return makeStringAttrValue(str)
 }
 
+// marshalBytesValue converts value to a B, or an empty slice as set by
+// EmptyBinaries.
+func (e *Encoder) marshalBytesValue(value reflect.Value) *dynamodb.AttributeValue {
+	if value.IsNil() {
+		return makeNullAttrValue()
+	}
+	if value.Len() == 0 {
+		return marshalEmpty(e.emptyBinaries, &dynamodb.AttributeValue{B: []byte{}})
+	}
+
+	return &dynamodb.AttributeValue{B: value.Bytes()}
+}
+
+// marshalEmpty returns the attribute value of an empty value for mode, where
+// literal is the value as it is and nil means leaving it out.
+func marshalEmpty(mode EmptyMode, literal *dynamodb.AttributeValue) *dynamodb.AttributeValue {
+	switch mode {
+	case EmptyOmit:
+		return nil
+	case EmptyLiteral:
+		return literal
+	}
+
+	return makeNullAttrValue()
+}
+
 func makeNullAttrValue() *dynamodb.AttributeValue {
 	return &dynamodb.AttributeValue{NULL: aws.Bool(true)}
 }
@@ -145,57 +543,189 @@ func makeNumberAttrValue(str string) *dynamodb.AttributeValue {
 	return &dynamodb.AttributeValue{N: aws.String(str)}
 }
 
-func marshalArrayValue(value reflect.Value) *dynamodb.AttributeValue {
+func (e *Encoder) marshalArrayValue(value reflect.Value, path string) (*dynamodb.AttributeValue, error) {
 	length := value.Len()
 
 	list := make([]*dynamodb.AttributeValue, length)
 	for i := 0; i < length; i++ {
-		list[i] = marshalValue(value.Index(i))
+		av, err := e.marshalValue(value.Index(i), indexPath(path, i))
+		if err != nil {
+			return nil, err
+		}
+		if av == nil {
+			av = makeNullAttrValue()
+		}
+		list[i] = av
 	}
 
-	return &dynamodb.AttributeValue{L: list}
+	return &dynamodb.AttributeValue{L: list}, nil
 }
 
-func marshalMapValue(value reflect.Value) *dynamodb.AttributeValue {
+func (e *Encoder) marshalMapValue(value reflect.Value, path string) (*dynamodb.AttributeValue, error) {
 	if value.IsNil() {
-		return makeNullAttrValue()
+		return makeNullAttrValue(), nil
+	}
+
+	if setType := mapSetType(value.Type()); setType != "" {
+		return e.marshalSetValue(value, setType, path)
+	}
+
+	m, err := e.marshalMap(value, path)
+	if m == nil || err != nil {
+		return nil, err
 	}
 
-	return &dynamodb.AttributeValue{M: marshalMap(value)}
+	return &dynamodb.AttributeValue{M: m}, nil
 }
 
-func marshalInterfaceValue(value reflect.Value) *dynamodb.AttributeValue {
+func (e *Encoder) marshalInterfaceValue(value reflect.Value, path string) (*dynamodb.AttributeValue, error) {
 	if value.IsNil() {
-		return makeNullAttrValue()
+		return makeNullAttrValue(), nil
 	}
 
-	return marshalValue(value.Elem())
+	return e.marshalValue(value.Elem(), path)
 }
 
-func marshalPtrValue(value reflect.Value) *dynamodb.AttributeValue {
+func (e *Encoder) marshalPtrValue(value reflect.Value, path string) (*dynamodb.AttributeValue, error) {
 	if value.IsNil() {
-		return makeNullAttrValue()
+		return makeNullAttrValue(), nil
 	}
 
-	return marshalValue(value.Elem())
+	return e.marshalValue(value.Elem(), path)
 }
 
-func marshalBytesValue(value reflect.Value) *dynamodb.AttributeValue {
+func (e *Encoder) marshalSliceValue(value reflect.Value, path string) (*dynamodb.AttributeValue, error) {
 	if value.IsNil() {
-		return makeNullAttrValue()
+		return makeNullAttrValue(), nil
 	}
 
-	return &dynamodb.AttributeValue{B: value.Bytes()}
+	return e.marshalArrayValue(value, path)
 }
 
-func marshalSliceValue(value reflect.Value) *dynamodb.AttributeValue {
-	if value.IsNil() {
-		return makeNullAttrValue()
+func (e *Encoder) marshalStructValue(value reflect.Value, path string) (*dynamodb.AttributeValue, error) {
+	m, err := e.marshalStruct(value, path)
+	if err != nil {
+		return nil, err
 	}
 
-	return marshalArrayValue(value)
+	return &dynamodb.AttributeValue{M: m}, nil
 }
 
-func marshalStructValue(value reflect.Value) *dynamodb.AttributeValue {
-	return &dynamodb.AttributeValue{M: marshalStruct(value)}
+// mapSetType returns the set type a map type is marshaled to, or "" if it is
+// marshaled as a M. Maps with struct{} values and maps with bool values and
+// non-string keys are treated as sets of their keys.
+func mapSetType(t reflect.Type) string {
+	elem := t.Elem()
+	isSet := (elem.Kind() == reflect.Struct && elem.NumField() == 0) ||
+		(elem.Kind() == reflect.Bool && t.Key().Kind() != reflect.String)
+	if !isSet {
+		return ""
+	}
+
+	switch t.Key().Kind() {
+	case reflect.String:
+		return "SS"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "NS"
+	}
+
+	return ""
 }
+
+// marshalSetValue marshals the elements of a slice or array, or the keys of a
+// map, to a SS, NS or BS. For maps with bool values only keys mapped to true
+// are members of the set. An empty set is marshaled as set by EmptySets, to
+// NULL by default, as dynamodb does not accept empty sets.
+func (e *Encoder) marshalSetValue(value reflect.Value, setType string, path string) (*dynamodb.AttributeValue, error) {
+	var elems []reflect.Value
+	switch value.Kind() {
+	case reflect.Map:
+		for _, k := range value.MapKeys() {
+			if value.Type().Elem().Kind() == reflect.Bool && !value.MapIndex(k).Bool() {
+				continue
+			}
+			elems = append(elems, k)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			elems = append(elems, value.Index(i))
+		}
+	default:
+		return nil, e.unsupported(value.Type(), path)
+	}
+
+	// dynamodb rejects sets with duplicate members, which slices and arrays
+	// may hold; only the first of them is kept. Nil pointers hold no member
+	// and are skipped.
+	av := &dynamodb.AttributeValue{}
+	seen := make(map[string]bool, len(elems))
+	for i, elem := range elems {
+		for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Ptr {
+			continue
+		}
+
+		var member *string
+		switch k := elem.Kind(); {
+		case setType == "SS" && k == reflect.String:
+			member = aws.String(elem.String())
+		case setType == "NS" && k == reflect.String:
+			member = aws.String(elem.String())
+		case setType == "NS" && k >= reflect.Int && k <= reflect.Int64:
+			member = marshalInt64Value(elem).N
+		case setType == "NS" && k >= reflect.Uint && k <= reflect.Uint64:
+			member = marshalUint64Value(elem).N
+		case setType == "NS" && (k == reflect.Float32 || k == reflect.Float64):
+			member = marshalFloat64Value(elem).N
+		case setType == "BS" && k == reflect.Slice && elem.Type().Elem().Kind() == reflect.Uint8:
+			if key := string(elem.Bytes()); !seen[key] {
+				seen[key] = true
+				av.BS = append(av.BS, elem.Bytes())
+			}
+			continue
+		default:
+			return nil, e.unsupported(elem.Type(), indexPath(path, i))
+		}
+
+		key := *member
+		if setType == "NS" {
+			// Numbers are equal by value, so "1" and "1.0" are the same
+			// member.
+			f, _, err := big.ParseFloat(key, 10, 256, big.ToNearestEven)
+			if err != nil {
+				return nil, &InvalidNumberError{Path: indexPath(path, i), Number: key}
+			}
+			key = f.Text('g', -1)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if setType == "SS" {
+			av.SS = append(av.SS, member)
+		} else {
+			av.NS = append(av.NS, member)
+		}
+	}
+
+	if len(av.SS) == 0 && len(av.NS) == 0 && len(av.BS) == 0 {
+		return marshalEmpty(e.emptySets, nil), nil
+	}
+
+	if value.Kind() == reflect.Map {
+		sort.Sort(byString(av.SS))
+		sort.Sort(byString(av.NS))
+	}
+
+	return av, nil
+}
+
+type byString []*string
+
+func (s byString) Len() int           { return len(s) }
+func (s byString) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byString) Less(i, j int) bool { return *s[i] < *s[j] }
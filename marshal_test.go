@@ -1,9 +1,20 @@
 package ddb_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"math"
+	"math/big"
+	"net"
+	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awsutil"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	. "github.com/runtakun/dynamodb-marshaler-go"
@@ -13,33 +24,142 @@ import (
 )
 
 type sample struct {
-	Str             string                 `json:"str"`
-	Bool            bool                   `json:"bool"`
-	Blob            []byte                 `json:"blob"`
-	Int             int                    `json:"int"`
-	Int8            int8                   `json:"int8"`
-	Int16           int16                  `json:"int16"`
-	Int32           int32                  `json:"int32"`
-	Int64           int64                  `json:"int64"`
-	Uint            uint                   `json:"uint"`
-	Uint8           uint8                  `json:"uint8"`
-	Uint16          uint16                 `json:"uint16"`
-	Uint32          uint32                 `json:"uint32"`
-	Uint64          uint64                 `json:"uint64"`
-	Float32         float32                `json:"float32"`
-	Float64         float64                `json:"float64"`
-	Arr             [3]int                 `json:"arr"`
-	InterfaceInt    interface{}            `json:"interface_int"`
-	InterfaceString interface{}            `json:"interface_str"`
-	Map             map[string]interface{} `json:"map"`
-	Ptr             *string                `json:"ptr"`
-	Slice           []string               `json:"slice"`
-	EmptySlice      []int                  `json:"empty_slice"`
-	Child           *child                 `json:"child"`
+	Str             string                 `dynamodb:"str"`
+	Bool            bool                   `dynamodb:"bool"`
+	Blob            []byte                 `dynamodb:"blob"`
+	Int             int                    `dynamodb:"int"`
+	Int8            int8                   `dynamodb:"int8"`
+	Int16           int16                  `dynamodb:"int16"`
+	Int32           int32                  `dynamodb:"int32"`
+	Int64           int64                  `dynamodb:"int64"`
+	Uint            uint                   `dynamodb:"uint"`
+	Uint8           uint8                  `dynamodb:"uint8"`
+	Uint16          uint16                 `dynamodb:"uint16"`
+	Uint32          uint32                 `dynamodb:"uint32"`
+	Uint64          uint64                 `dynamodb:"uint64"`
+	Float32         float32                `dynamodb:"float32"`
+	Float64         float64                `dynamodb:"float64"`
+	Arr             [3]int                 `dynamodb:"arr"`
+	InterfaceInt    interface{}            `dynamodb:"interface_int"`
+	InterfaceString interface{}            `dynamodb:"interface_str"`
+	Map             map[string]interface{} `dynamodb:"map"`
+	Ptr             *string                `dynamodb:"ptr"`
+	Slice           []string               `dynamodb:"slice"`
+	EmptySlice      []int                  `dynamodb:"empty_slice"`
+	Child           *child                 `dynamodb:"child"`
 }
 
 type child struct {
-	Content string `json:"content"`
+	Content string `dynamodb:"content"`
+}
+
+type money struct {
+	Cents int64
+}
+
+func (m money) MarshalDynamoDBAttributeValue() (*dynamodb.AttributeValue, error) {
+	return &dynamodb.AttributeValue{N: aws.String(fmt.Sprintf("%d.%02d", m.Cents/100, m.Cents%100))}, nil
+}
+
+func (m *money) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	if av.N == nil {
+		return errors.New("money must be a number")
+	}
+	var units, cents int64
+	if _, err := fmt.Sscanf(*av.N, "%d.%d", &units, &cents); err != nil {
+		return err
+	}
+	m.Cents = units*100 + cents
+	return nil
+}
+
+type color int
+
+const (
+	red color = iota
+	blue
+)
+
+func (c *color) MarshalDynamoDBAttributeValue() (*dynamodb.AttributeValue, error) {
+	switch *c {
+	case red:
+		return &dynamodb.AttributeValue{S: aws.String("RED")}, nil
+	case blue:
+		return &dynamodb.AttributeValue{S: aws.String("BLUE")}, nil
+	}
+	return nil, errors.New("unknown color")
+}
+
+func (c *color) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	switch strings.ToUpper(aws.StringValue(av.S)) {
+	case "RED":
+		*c = red
+	case "BLUE":
+		*c = blue
+	default:
+		return errors.New("unknown color")
+	}
+	return nil
+}
+
+type point struct {
+	X, Y int
+}
+
+func (p point) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"coords":[%d,%d],"label":"p"}`, p.X, p.Y)), nil
+}
+
+func (p *point) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Coords []int `json:"coords"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if len(v.Coords) != 2 {
+		return errors.New("invalid point")
+	}
+	p.X, p.Y = v.Coords[0], v.Coords[1]
+	return nil
+}
+
+type device struct {
+	IP    net.IP  `dynamodb:"ip"`
+	Loc   point   `dynamodb:"loc"`
+	Route []point `dynamodb:"route"`
+}
+
+type BaseEntity struct {
+	PK        string    `dynamodb:"pk"`
+	SK        string    `dynamodb:"sk"`
+	CreatedAt time.Time `dynamodb:"created_at"`
+}
+
+type Audit struct {
+	By   string `dynamodb:"by"`
+	Note string `dynamodb:"note"`
+}
+
+type Labels struct {
+	Note string `dynamodb:"note"`
+}
+
+type user struct {
+	BaseEntity
+	*Audit
+	Labels
+	Name  string `dynamodb:"name"`
+	SK    string `dynamodb:"sk"`
+	Owner Audit  `dynamodb:"owner"`
+}
+
+type product struct {
+	Price   money            `dynamodb:"price"`
+	Sale    *money           `dynamodb:"sale"`
+	History []money          `dynamodb:"history"`
+	Prices  map[string]money `dynamodb:"prices"`
+	Color   color            `dynamodb:"color"`
 }
 
 var _ = Describe("Marshal", func() {
@@ -229,4 +349,733 @@ var _ = Describe("Marshal", func() {
 
 	})
 
+	Context("EmptyStrings and EmptySets", func() {
+		type doc struct {
+			Str    string            `dynamodb:"str"`
+			Blob   []byte            `dynamodb:"blob"`
+			Empty  []byte            `dynamodb:"empty"`
+			Ptr    *string           `dynamodb:"ptr"`
+			Tags   []string          `dynamodb:"tags,stringset"`
+			Nums   map[int]bool      `dynamodb:"nums"`
+			List   []string          `dynamodb:"list"`
+			Labels map[string]string `dynamodb:"labels"`
+			Kept   string            `dynamodb:"kept"`
+		}
+
+		var s *doc
+
+		BeforeEach(func() {
+			empty := ""
+			s = &doc{
+				Empty:  []byte{},
+				Ptr:    &empty,
+				Tags:   []string{},
+				Nums:   map[int]bool{1: false},
+				List:   []string{"", "a"},
+				Labels: map[string]string{"a": "", "b": "x"},
+				Kept:   "kept",
+			}
+		})
+
+		null := &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+
+		It("should encode empty values as NULL by default", func() {
+			sut, err := MarshalE(s)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, name := range []string{"str", "blob", "ptr", "tags", "nums"} {
+				Expect(sut[name]).To(Equal(null), name)
+			}
+			Expect(sut["empty"]).To(Equal(&dynamodb.AttributeValue{B: []byte{}}))
+			Expect(sut["list"].L[0]).To(Equal(null))
+			Expect(sut["labels"].M["a"]).To(Equal(null))
+		})
+
+		It("should leave out empty values with EmptyOmit", func() {
+			sut, err := MarshalE(s, EmptyStrings(EmptyOmit), EmptySets(EmptyOmit))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sut).To(HaveLen(5))
+			Expect(sut["blob"]).To(Equal(null))
+			Expect(sut["empty"]).To(Equal(&dynamodb.AttributeValue{B: []byte{}}))
+			Expect(sut["list"].L).To(Equal([]*dynamodb.AttributeValue{null, {S: aws.String("a")}}))
+			Expect(sut["labels"].M).To(Equal(map[string]*dynamodb.AttributeValue{"b": {S: aws.String("x")}}))
+			Expect(*sut["kept"].S).To(Equal("kept"))
+		})
+
+		It("should encode empty binaries as set by EmptyBinaries", func() {
+			sut, err := MarshalE(s, EmptyBinaries(EmptyOmit))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut).NotTo(HaveKey("empty"))
+			Expect(sut["blob"]).To(Equal(null))
+
+			sut, err = MarshalE(s, EmptyBinaries(EmptyNull))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut["empty"]).To(Equal(null))
+
+			av, err := MarshalAttributeValue([]byte{}, EmptyBinaries(EmptyOmit))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(av).To(Equal(null))
+		})
+
+		It("should encode empty strings literally with EmptyLiteral", func() {
+			sut, err := MarshalE(s, EmptyStrings(EmptyLiteral))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sut["str"]).To(Equal(&dynamodb.AttributeValue{S: aws.String("")}))
+			Expect(sut["blob"]).To(Equal(null))
+			Expect(sut["empty"]).To(Equal(&dynamodb.AttributeValue{B: []byte{}}))
+			Expect(sut["ptr"]).To(Equal(&dynamodb.AttributeValue{S: aws.String("")}))
+			Expect(sut["list"].L[0]).To(Equal(&dynamodb.AttributeValue{S: aws.String("")}))
+			Expect(sut["labels"].M["a"]).To(Equal(&dynamodb.AttributeValue{S: aws.String("")}))
+			Expect(sut["tags"]).To(Equal(null))
+		})
+
+		It("should keep leaving out omitempty fields", func() {
+			sut, err := MarshalE(&struct {
+				Str string `dynamodb:"str,omitempty"`
+			}{}, EmptyStrings(EmptyLiteral))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut).To(BeEmpty())
+		})
+
+		It("should encode an omitted single value as NULL", func() {
+			av, err := MarshalAttributeValue("", EmptyStrings(EmptyOmit))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(av).To(Equal(null))
+		})
+
+		It("should apply to strings from MarshalJSON", func() {
+			v := &struct {
+				Meta json.RawMessage `dynamodb:"meta"`
+			}{json.RawMessage(`{"a": "", "b": ["", 1]}`)}
+
+			sut, err := MarshalE(v, UseJSONMarshaler(), EmptyStrings(EmptyOmit))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut["meta"].M).To(Equal(map[string]*dynamodb.AttributeValue{
+				"b": {L: []*dynamodb.AttributeValue{null, {N: aws.String("1")}}},
+			}))
+
+			sut, err = MarshalE(v, UseJSONMarshaler(), EmptyStrings(EmptyLiteral))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut["meta"].M["a"]).To(Equal(&dynamodb.AttributeValue{S: aws.String("")}))
+		})
+
+		It("should not accept empty sets", func() {
+			_, err := MarshalE(s, EmptySets(EmptyLiteral))
+			Expect(err).To(HaveOccurred())
+
+			_, err = NewEncoder(EmptySets(EmptyLiteral)).EncodeAttributeValue([]string{"a"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should round trip literal empty strings", func() {
+			item, err := MarshalE(s, EmptyStrings(EmptyLiteral))
+			Expect(err).NotTo(HaveOccurred())
+
+			var sut doc
+			Expect(Unmarshal(item, &sut)).To(Succeed())
+			Expect(sut.Ptr).To(Equal(aws.String("")))
+			Expect(sut.Empty).To(Equal([]byte{}))
+			Expect(sut.List).To(Equal([]string{"", "a"}))
+		})
+	})
+
+	Context("MarshalE", func() {
+		type unsupported struct {
+			Name  string        `dynamodb:"name"`
+			Items []interface{} `dynamodb:"items"`
+		}
+
+		It("should return item like Marshal", func() {
+			sut, err := MarshalE(&child{Content: "foo"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*sut["content"].S).To(Equal("foo"))
+		})
+
+		It("should return UnsupportedTypeError with path", func() {
+			_, err := MarshalE(&unsupported{
+				Name:  "foo",
+				Items: []interface{}{"a", map[string]interface{}{"fn": func() {}}},
+			})
+			Expect(err).To(HaveOccurred())
+
+			e, ok := err.(*UnsupportedTypeError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("items[1].fn"))
+			Expect(e.Type.String()).To(Equal("func()"))
+		})
+
+		It("should return UnsupportedTypeError for non-string map key", func() {
+			_, err := MarshalE(map[int]string{1: "foo"})
+			Expect(err).To(BeAssignableToTypeOf(&UnsupportedTypeError{}))
+		})
+
+		It("should return InvalidMarshalError for invalid input", func() {
+			_, err := MarshalE("foo")
+			Expect(err).To(BeAssignableToTypeOf(&InvalidMarshalError{}))
+
+			_, err = MarshalE((*child)(nil))
+			Expect(err).To(BeAssignableToTypeOf(&InvalidMarshalError{}))
+
+			_, err = MarshalE(nil)
+			Expect(err).To(BeAssignableToTypeOf(&InvalidMarshalError{}))
+		})
+
+		It("should prepend attribute names to paths by WithPath", func() {
+			_, err := MarshalAttributeValue([]interface{}{func() {}})
+			err = WithPath(err, "items")
+			Expect(err.(*UnsupportedTypeError).Path).To(Equal("items[0]"))
+
+			err = WithPath(&UnsupportedTypeError{Path: "fn", Type: reflect.TypeOf(func() {})}, "items[1]")
+			Expect(err.(*UnsupportedTypeError).Path).To(Equal("items[1].fn"))
+
+			Expect(WithPath(io.EOF, "items")).To(Equal(io.EOF))
+		})
+
+		It("should make Marshal leave out unsupported types", func() {
+			sut := Marshal(&unsupported{
+				Name:  "foo",
+				Items: []interface{}{"a", make(chan int), map[string]interface{}{"fn": func() {}}},
+			})
+			Expect(sut).To(Equal(map[string]*dynamodb.AttributeValue{
+				"name": {S: aws.String("foo")},
+				"items": {L: []*dynamodb.AttributeValue{
+					{S: aws.String("a")},
+					{NULL: aws.Bool(true)},
+					{M: map[string]*dynamodb.AttributeValue{}},
+				}},
+			}))
+
+			Expect(Marshal(map[string]interface{}{"ch": make(chan int), "c": complex(1, 2)})).To(BeEmpty())
+			Expect(Marshal(map[int]string{1: "foo"})).To(BeNil())
+		})
+	})
+
+	Context("omitempty", func() {
+		type sparse struct {
+			PK     string            `dynamodb:"pk"`
+			GSI    string            `dynamodb:"gsi,omitempty"`
+			Count  int               `dynamodb:"count,omitempty"`
+			Flag   bool              `dynamodb:"flag,omitempty"`
+			Ptr    *string           `dynamodb:"ptr,omitempty"`
+			Slice  []string          `dynamodb:"slice,omitempty"`
+			Map    map[string]string `dynamodb:"map,omitempty"`
+			Legacy string            `dynamodb:"legacy,omitifempty"`
+			Null   string            `dynamodb:"null"`
+		}
+
+		It("should omit empty values", func() {
+			sut := Marshal(&sparse{PK: "pk", Slice: []string{}, Map: map[string]string{}})
+			Expect(sut).Should(HaveLen(2))
+			Expect(*sut["pk"].S).To(Equal("pk"))
+			Expect(*sut["null"].NULL).To(BeTrue())
+		})
+
+		It("should not omit non-empty values", func() {
+			ptr := ""
+			sut := Marshal(&sparse{
+				PK:     "pk",
+				GSI:    "gsi",
+				Count:  1,
+				Flag:   true,
+				Ptr:    &ptr,
+				Slice:  []string{"a"},
+				Map:    map[string]string{"a": "b"},
+				Legacy: "legacy",
+			})
+			Expect(sut).Should(HaveLen(9))
+			Expect(*sut["gsi"].S).To(Equal("gsi"))
+			Expect(*sut["count"].N).To(Equal("1"))
+			Expect(*sut["legacy"].S).To(Equal("legacy"))
+		})
+	})
+
+	Context("dynamodb tag", func() {
+		type entity struct {
+			ID      string `dynamodb:"pk" json:"id"`
+			Name    string `json:"name"`
+			Secret  string `dynamodb:"-" json:"secret"`
+			Hidden  string `dynamodb:"hidden" json:"-"`
+			Version int    `dynamodbav:"ver" json:"version"`
+		}
+
+		var s *entity
+
+		BeforeEach(func() {
+			s = &entity{ID: "1", Name: "foo", Secret: "bar", Hidden: "baz", Version: 2}
+		})
+
+		It("should read only dynamodb tag by default", func() {
+			sut := Marshal(s)
+			Expect(sut).Should(HaveLen(4))
+			Expect(*sut["pk"].S).To(Equal("1"))
+			Expect(*sut["Name"].S).To(Equal("foo"))
+			Expect(*sut["hidden"].S).To(Equal("baz"))
+			Expect(*sut["Version"].N).To(Equal("2"))
+		})
+
+		It("should fall back to json tag when configured", func() {
+			sut := Marshal(s, TagNames("dynamodb", "json"))
+			Expect(sut).Should(HaveLen(4))
+			Expect(*sut["pk"].S).To(Equal("1"))
+			Expect(*sut["name"].S).To(Equal("foo"))
+			Expect(*sut["hidden"].S).To(Equal("baz"))
+			Expect(*sut["version"].N).To(Equal("2"))
+		})
+
+		It("should use only configured tag names", func() {
+			sut := Marshal(s, TagNames("dynamodb", "dynamodbav"))
+			Expect(sut).Should(HaveLen(4))
+			Expect(*sut["pk"].S).To(Equal("1"))
+			Expect(*sut["Name"].S).To(Equal("foo"))
+			Expect(*sut["hidden"].S).To(Equal("baz"))
+			Expect(*sut["ver"].N).To(Equal("2"))
+		})
+	})
+
+	Context("set", func() {
+		type sets struct {
+			Tags     []string            `dynamodb:"tags,stringset"`
+			Scores   []float64           `dynamodb:"scores,numberset"`
+			IDs      [2]int              `dynamodb:"ids,numberset"`
+			Decimals []string            `dynamodb:"decimals,numberset"`
+			Blobs    [][]byte            `dynamodb:"blobs,binaryset"`
+			Flagged  map[string]bool     `dynamodb:"flagged,stringset"`
+			Members  map[string]struct{} `dynamodb:"members"`
+			Numbers  map[int]bool        `dynamodb:"numbers"`
+			Empty    []string            `dynamodb:"empty,stringset"`
+			List     []string            `dynamodb:"list"`
+		}
+
+		var sut map[string]*dynamodb.AttributeValue
+
+		BeforeEach(func() {
+			sut = Marshal(&sets{
+				Tags:     []string{"a", "b"},
+				Scores:   []float64{1.5, 2},
+				IDs:      [2]int{1, 2},
+				Decimals: []string{"0.10"},
+				Blobs:    [][]byte{[]byte{0x1}, []byte{0x2}},
+				Flagged:  map[string]bool{"x": true, "y": false, "z": true},
+				Members:  map[string]struct{}{"b": struct{}{}, "a": struct{}{}},
+				Numbers:  map[int]bool{3: true, 1: true, 2: false},
+				Empty:    []string{},
+				List:     []string{"a"},
+			})
+		})
+
+		It("should be stringset tagged field to SS", func() {
+			Expect(sut["tags"].SS).To(Equal([]*string{aws.String("a"), aws.String("b")}))
+			Expect(sut["tags"].L).To(BeNil())
+		})
+
+		It("should be numberset tagged field to NS", func() {
+			Expect(sut["scores"].NS).To(Equal([]*string{aws.String("1.5"), aws.String("2")}))
+			Expect(sut["ids"].NS).To(Equal([]*string{aws.String("1"), aws.String("2")}))
+			Expect(sut["decimals"].NS).To(Equal([]*string{aws.String("0.10")}))
+		})
+
+		It("should be binaryset tagged field to BS", func() {
+			Expect(sut["blobs"].BS).To(Equal([][]byte{[]byte{0x1}, []byte{0x2}}))
+		})
+
+		It("should drop duplicate members", func() {
+			sut := Marshal(&sets{
+				Tags:     []string{"a", "b", "a"},
+				IDs:      [2]int{1, 1},
+				Decimals: []string{"1", "0.10", "1.0", "0.1"},
+				Blobs:    [][]byte{[]byte{0x1}, []byte{0x1}},
+			})
+			Expect(sut["tags"].SS).To(Equal([]*string{aws.String("a"), aws.String("b")}))
+			Expect(sut["ids"].NS).To(Equal([]*string{aws.String("1")}))
+			Expect(sut["decimals"].NS).To(Equal([]*string{aws.String("1"), aws.String("0.10")}))
+			Expect(sut["blobs"].BS).To(Equal([][]byte{[]byte{0x1}}))
+		})
+
+		It("should be map keys to set", func() {
+			Expect(sut["flagged"].SS).To(Equal([]*string{aws.String("x"), aws.String("z")}))
+			Expect(sut["members"].SS).To(Equal([]*string{aws.String("a"), aws.String("b")}))
+			Expect(sut["numbers"].NS).To(Equal([]*string{aws.String("1"), aws.String("3")}))
+		})
+
+		It("should be empty set to null value", func() {
+			Expect(*sut["empty"].NULL).To(BeTrue())
+		})
+
+		It("should keep untagged slice as list", func() {
+			Expect(sut["list"].L).Should(HaveLen(1))
+		})
+
+		It("should return UnsupportedTypeError for invalid set element", func() {
+			type invalid struct {
+				Tags []int `dynamodb:"tags,stringset"`
+			}
+			_, err := MarshalE(&invalid{Tags: []int{1}})
+			Expect(err).To(HaveOccurred())
+
+			e, ok := err.(*UnsupportedTypeError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("tags[0]"))
+		})
+
+		It("should return InvalidNumberError for numberset member which is not a number", func() {
+			_, err := MarshalE(&sets{Decimals: []string{"1", "one"}})
+			Expect(err).To(HaveOccurred())
+
+			e, ok := err.(*InvalidNumberError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("decimals[1]"))
+			Expect(e.Number).To(Equal("one"))
+			Expect(func() { Marshal(&sets{Decimals: []string{"one"}}) }).To(Panic())
+		})
+
+		It("should skip nil pointer members", func() {
+			type pointers struct {
+				Tags  []*string `dynamodb:"tags,stringset"`
+				Empty []*string `dynamodb:"empty,stringset"`
+			}
+			sut := Marshal(&pointers{
+				Tags:  []*string{aws.String("a"), nil, aws.String("b")},
+				Empty: []*string{nil},
+			})
+			Expect(sut["tags"].SS).To(Equal([]*string{aws.String("a"), aws.String("b")}))
+			Expect(*sut["empty"].NULL).To(BeTrue())
+		})
+	})
+
+	Context("Marshaler", func() {
+		It("should be used at every nesting level", func() {
+			sut := Marshal(&product{
+				Price:   money{1234},
+				History: []money{{100}, {205}},
+				Prices:  map[string]money{"jpy": {5}},
+				Color:   blue,
+			})
+
+			Expect(*sut["price"].N).To(Equal("12.34"))
+			Expect(*sut["sale"].NULL).To(BeTrue())
+			Expect(*sut["history"].L[1].N).To(Equal("2.05"))
+			Expect(*sut["prices"].M["jpy"].N).To(Equal("0.05"))
+			Expect(*sut["color"].S).To(Equal("BLUE"))
+		})
+
+		It("should return MarshalerError with path", func() {
+			_, err := MarshalE(&product{Color: color(9)})
+			Expect(err).To(HaveOccurred())
+
+			e, ok := err.(*MarshalerError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("color"))
+		})
+
+		It("should return MarshalerError without path for a single value", func() {
+			c := color(9)
+			_, err := MarshalAttributeValue(&c)
+			Expect(err).To(BeAssignableToTypeOf(&MarshalerError{}))
+			Expect(err.Error()).NotTo(ContainSubstring(" at "))
+		})
+	})
+
+	Context("encoding.TextMarshaler and json.Marshaler", func() {
+		var s *device
+
+		BeforeEach(func() {
+			s = &device{
+				IP:    net.ParseIP("192.0.2.1"),
+				Loc:   point{1, 2},
+				Route: []point{{3, 4}},
+			}
+		})
+
+		It("should be ignored by default", func() {
+			sut := Marshal(s)
+			Expect(sut["ip"].S).To(BeNil())
+			Expect(*sut["loc"].M["X"].N).To(Equal("1"))
+		})
+
+		It("should store TextMarshaler as string", func() {
+			sut := Marshal(s, UseTextMarshaler())
+			Expect(*sut["ip"].S).To(Equal("192.0.2.1"))
+		})
+
+		It("should store json.Marshaler as document", func() {
+			sut := Marshal(s, UseJSONMarshaler())
+			loc := sut["loc"].M
+			Expect(loc).Should(HaveLen(2))
+			Expect(*loc["coords"].L[0].N).To(Equal("1"))
+			Expect(*loc["coords"].L[1].N).To(Equal("2"))
+			Expect(*loc["label"].S).To(Equal("p"))
+			Expect(*sut["route"].L[0].M["coords"].L[1].N).To(Equal("4"))
+		})
+	})
+
+	Context("time", func() {
+		type event struct {
+			At       time.Time     `dynamodb:"at"`
+			Expires  time.Time     `dynamodb:"expires,unixtime"`
+			Created  *time.Time    `dynamodb:"created,unixmilli"`
+			Deleted  *time.Time    `dynamodb:"deleted,unixtime"`
+			Skipped  time.Time     `dynamodb:"skipped,omitempty"`
+			Interval time.Duration `dynamodb:"interval"`
+		}
+
+		var sut map[string]*dynamodb.AttributeValue
+
+		BeforeEach(func() {
+			at := time.Date(2020, 5, 18, 12, 34, 56, 789000000, time.UTC)
+			sut = Marshal(&event{
+				At:       at,
+				Expires:  at,
+				Created:  &at,
+				Interval: 90 * time.Second,
+			})
+		})
+
+		It("should be time.Time to RFC3339 string by default", func() {
+			Expect(*sut["at"].S).To(Equal("2020-05-18T12:34:56.789Z"))
+		})
+
+		It("should be unixtime tagged time.Time to seconds", func() {
+			Expect(*sut["expires"].N).To(Equal("1589805296"))
+		})
+
+		It("should be unixmilli tagged time.Time to milliseconds", func() {
+			Expect(*sut["created"].N).To(Equal("1589805296789"))
+		})
+
+		It("should be nil time pointer to null value", func() {
+			Expect(*sut["deleted"].NULL).To(BeTrue())
+		})
+
+		It("should omit zero time with omitempty", func() {
+			Expect(sut).NotTo(HaveKey("skipped"))
+		})
+
+		It("should be time.Duration to nanoseconds", func() {
+			Expect(*sut["interval"].N).To(Equal("90000000000"))
+		})
+	})
+
+	Context("embedded struct", func() {
+		var sut map[string]*dynamodb.AttributeValue
+
+		BeforeEach(func() {
+			sut = Marshal(&user{
+				BaseEntity: BaseEntity{PK: "USER#1", SK: "base", CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+				Audit:      &Audit{By: "admin", Note: "audit"},
+				Labels:     Labels{Note: "labels"},
+				Name:       "foo",
+				SK:         "PROFILE",
+				Owner:      Audit{By: "owner"},
+			})
+		})
+
+		It("should flatten fields of embedded struct", func() {
+			Expect(*sut["pk"].S).To(Equal("USER#1"))
+			Expect(*sut["created_at"].S).To(Equal("2020-01-02T03:04:05Z"))
+			Expect(*sut["by"].S).To(Equal("admin"))
+			Expect(sut).NotTo(HaveKey("BaseEntity"))
+			Expect(sut).NotTo(HaveKey("Audit"))
+		})
+
+		It("should let shallower field shadow embedded one", func() {
+			Expect(*sut["sk"].S).To(Equal("PROFILE"))
+		})
+
+		It("should drop ambiguous fields at the same depth", func() {
+			Expect(sut).NotTo(HaveKey("note"))
+		})
+
+		It("should keep named struct field nested", func() {
+			Expect(*sut["owner"].M["by"].S).To(Equal("owner"))
+		})
+
+		It("should skip fields of nil embedded pointer", func() {
+			sut := Marshal(&user{Name: "foo"})
+			Expect(sut).NotTo(HaveKey("by"))
+			Expect(*sut["name"].S).To(Equal("foo"))
+		})
+	})
+
+	Context("concurrent use", func() {
+		It("should marshal the same type from many goroutines", func() {
+			var wg sync.WaitGroup
+			results := make([]map[string]*dynamodb.AttributeValue, 8)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i] = Marshal(&user{Name: strconv.Itoa(i)}, TagNames("dynamodb"))
+				}(i)
+			}
+			wg.Wait()
+
+			for i, sut := range results {
+				Expect(*sut["name"].S).To(Equal(strconv.Itoa(i)))
+			}
+		})
+	})
+
+	Context("Encoder", func() {
+		type doc struct {
+			Name string   `dynamodbav:"n" json:"name"`
+			Note string   `json:"note"`
+			Tags []string `json:"tags,stringset"`
+		}
+
+		It("should encode with its options", func() {
+			enc := NewEncoder(TagNames("dynamodbav", "json"), EmptyStrings(EmptyOmit), EmptySets(EmptyOmit))
+
+			sut, err := enc.Encode(&doc{Name: "foo"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut).To(Equal(map[string]*dynamodb.AttributeValue{"n": {S: aws.String("foo")}}))
+
+			av, err := enc.EncodeAttributeValue(doc{Note: "bar"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(av).To(Equal(&dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+				"note": {S: aws.String("bar")},
+			}}))
+		})
+
+		It("should encode like MarshalE without options", func() {
+			s := &doc{Name: "foo"}
+			want, err := MarshalE(s)
+			Expect(err).NotTo(HaveOccurred())
+
+			sut, err := NewEncoder().Encode(s)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut).To(Equal(want))
+		})
+
+		It("should report invalid values", func() {
+			_, err := NewEncoder().Encode("foo")
+			Expect(err).To(BeAssignableToTypeOf(&InvalidMarshalError{}))
+		})
+
+		It("should be safe for concurrent use", func() {
+			enc := NewEncoder(TagNames("json"), EmptyStrings(EmptyLiteral))
+			var wg sync.WaitGroup
+			results := make([]map[string]*dynamodb.AttributeValue, 8)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], _ = enc.Encode(&doc{Name: strconv.Itoa(i)})
+				}(i)
+			}
+			wg.Wait()
+
+			for i, item := range results {
+				Expect(*item["name"].S).To(Equal(strconv.Itoa(i)))
+				Expect(*item["note"].S).To(BeEmpty())
+			}
+		})
+	})
+
+	Context("TypeEncoder", func() {
+		bigIntType := reflect.TypeOf((*big.Int)(nil))
+		ipType := reflect.TypeOf(net.IP(nil))
+
+		encodeBigInt := TypeEncoder(bigIntType, func(v reflect.Value) (*dynamodb.AttributeValue, error) {
+			if v.IsNil() {
+				return nil, nil
+			}
+			return &dynamodb.AttributeValue{N: aws.String(v.Interface().(*big.Int).String())}, nil
+		})
+		encodeIP := TypeEncoder(ipType, func(v reflect.Value) (*dynamodb.AttributeValue, error) {
+			return &dynamodb.AttributeValue{S: aws.String(v.Interface().(net.IP).String())}, nil
+		})
+
+		type account struct {
+			Balance *big.Int            `dynamodb:"balance"`
+			Debt    *big.Int            `dynamodb:"debt"`
+			IP      net.IP              `dynamodb:"ip"`
+			History []*big.Int          `dynamodb:"history"`
+			Limits  map[string]*big.Int `dynamodb:"limits"`
+		}
+
+		It("should encode registered types by their function", func() {
+			n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+			sut, err := MarshalE(&account{
+				Balance: n,
+				IP:      net.ParseIP("192.0.2.1"),
+				History: []*big.Int{big.NewInt(1), nil},
+				Limits:  map[string]*big.Int{"daily": big.NewInt(-5)},
+			}, encodeBigInt, encodeIP)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(*sut["balance"].N).To(Equal("123456789012345678901234567890"))
+			Expect(*sut["debt"].NULL).To(BeTrue())
+			Expect(*sut["ip"].S).To(Equal("192.0.2.1"))
+			Expect(sut["history"].L).To(Equal([]*dynamodb.AttributeValue{
+				{N: aws.String("1")},
+				{NULL: aws.Bool(true)},
+			}))
+			Expect(*sut["limits"].M["daily"].N).To(Equal("-5"))
+		})
+
+		It("should take precedence over Marshaler", func() {
+			sut, err := MarshalAttributeValue(money{Cents: 1999}, TypeEncoder(reflect.TypeOf(money{}), func(v reflect.Value) (*dynamodb.AttributeValue, error) {
+				return &dynamodb.AttributeValue{S: aws.String("custom")}, nil
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*sut.S).To(Equal("custom"))
+		})
+
+		It("should encode a registered item type", func() {
+			sut, err := NewEncoder(TypeEncoder(reflect.TypeOf(account{}), func(v reflect.Value) (*dynamodb.AttributeValue, error) {
+				return &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("a")}}}, nil
+			})).Encode(&account{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut).To(Equal(map[string]*dynamodb.AttributeValue{"id": {S: aws.String("a")}}))
+		})
+
+		It("should report errors with path", func() {
+			_, err := MarshalE(&account{IP: net.IP{1}}, TypeEncoder(ipType, func(v reflect.Value) (*dynamodb.AttributeValue, error) {
+				return nil, errors.New("bad ip")
+			}))
+			Expect(err).To(BeAssignableToTypeOf(&MarshalerError{}))
+			Expect(err.(*MarshalerError).Path).To(Equal("ip"))
+			Expect(err.(*MarshalerError).Err).To(MatchError("bad ip"))
+		})
+
+		It("should only apply to the encoder it is given to", func() {
+			enc := NewEncoder(encodeIP)
+			NewEncoder(encodeIP, encodeBigInt)
+
+			sut, err := enc.Encode(&account{Balance: big.NewInt(1)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut["balance"].N).To(BeNil())
+
+			sut = Marshal(&account{IP: net.ParseIP("192.0.2.1")})
+			Expect(sut["ip"].S).To(BeNil())
+		})
+	})
+
+	Context("MarshalAttributeValue", func() {
+		It("should marshal a single value", func() {
+			av, err := MarshalAttributeValue([]int{1, 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(av.L).To(HaveLen(2))
+			Expect(*av.L[1].N).To(Equal("2"))
+		})
+
+		It("should marshal nil as NULL", func() {
+			av, err := MarshalAttributeValue(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*av.NULL).To(BeTrue())
+		})
+
+		It("should use Marshaler", func() {
+			av, err := MarshalAttributeValue(&money{Cents: 1999})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*av.N).To(Equal("19.99"))
+		})
+
+		It("should report unsupported value", func() {
+			_, err := MarshalAttributeValue(make(chan int))
+			Expect(err).To(BeAssignableToTypeOf(&UnsupportedTypeError{}))
+		})
+	})
 })
@@ -0,0 +1,197 @@
+package ddb
+
+import (
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// An EncodeOption configures how Marshal or an Encoder encodes values.
+type EncodeOption interface {
+	applyEncode(*Encoder)
+}
+
+// A DecodeOption configures how Unmarshal or a Decoder decodes attribute
+// values.
+type DecodeOption interface {
+	applyDecode(*Decoder)
+}
+
+type encodeOptionFunc func(*Encoder)
+
+func (f encodeOptionFunc) applyEncode(e *Encoder) { f(e) }
+
+type decodeOptionFunc func(*Decoder)
+
+func (f decodeOptionFunc) applyDecode(d *Decoder) { f(d) }
+
+// An EmptyMode selects how Marshal encodes empty strings, binaries and sets.
+type EmptyMode int
+
+const (
+	// EmptyNull encodes empty values as NULL. It is the default for strings
+	// and sets.
+	EmptyNull EmptyMode = iota
+
+	// EmptyOmit leaves attributes with empty values out of the item or map
+	// they belong to. Empty values in lists are encoded as NULL.
+	EmptyOmit
+
+	// EmptyLiteral encodes empty strings and binaries as an empty S or B,
+	// which dynamodb accepts for attributes other than keys. It is the
+	// default for binaries.
+	EmptyLiteral
+)
+
+// EmptyStrings sets how Marshal encodes empty strings, including those
+// returned by MarshalText and MarshalJSON. The default is EmptyNull; the
+// omitempty tag option still leaves such fields out regardless of mode.
+func EmptyStrings(mode EmptyMode) EncodeOption {
+	return encodeOptionFunc(func(e *Encoder) {
+		e.emptyStrings = mode
+	})
+}
+
+// EmptyBinaries sets how Marshal encodes empty, non-nil []byte values. The
+// default is EmptyLiteral. A nil []byte is encoded as NULL like other nil
+// slices.
+func EmptyBinaries(mode EmptyMode) EncodeOption {
+	return encodeOptionFunc(func(e *Encoder) {
+		e.emptyBinaries = mode
+	})
+}
+
+// EmptySets sets how Marshal encodes sets without members, which dynamodb
+// rejects. The default is EmptyNull. EmptyLiteral is not supported; an
+// Encoder configured with it fails every call with an error.
+func EmptySets(mode EmptyMode) EncodeOption {
+	return encodeOptionFunc(func(e *Encoder) {
+		e.emptySets = mode
+	})
+}
+
+// Strict makes Unmarshal return an *UnmarshalTypeError when an attribute
+// value cannot be stored in the destination, instead of skipping it.
+func Strict() DecodeOption {
+	return decodeOptionFunc(func(d *Decoder) {
+		d.strict = true
+	})
+}
+
+// TruncateFloats makes Unmarshal accept numbers with a fractional part for
+// integer fields, truncating them toward zero. Without it such numbers are
+// reported as an *UnmarshalNumberError.
+func TruncateFloats() DecodeOption {
+	return decodeOptionFunc(func(d *Decoder) {
+		d.truncateFloats = true
+	})
+}
+
+// UseNumber makes Unmarshal store numbers decoded into an interface{} as a
+// json.Number holding their text, instead of as a float64.
+func UseNumber() DecodeOption {
+	return decodeOptionFunc(func(d *Decoder) {
+		d.useNumber = true
+	})
+}
+
+// PreserveOnNull makes Unmarshal leave the destination of a NULL attribute
+// value as it is. By default NULL sets it to its zero value, so that
+// decoding into a reused value does not keep data of the previous item.
+func PreserveOnNull() DecodeOption {
+	return decodeOptionFunc(func(d *Decoder) {
+		d.preserveOnNull = true
+	})
+}
+
+// An Option configures both Marshal and Unmarshal.
+type Option interface {
+	EncodeOption
+	DecodeOption
+}
+
+type option struct {
+	encode func(*Encoder)
+	decode func(*Decoder)
+}
+
+func (o option) applyEncode(e *Encoder) { o.encode(e) }
+
+func (o option) applyDecode(d *Decoder) { o.decode(d) }
+
+// TagNames sets the struct tag keys read for attribute names and options.
+// The first key present on a field wins. The default is "dynamodb" alone;
+// pass e.g. TagNames("dynamodb", "json") to fall back to the json tag as
+// earlier versions did, or TagNames("dynamodb", "dynamodbav") to honor tags
+// written for the AWS SDK.
+func TagNames(names ...string) Option {
+	tags := newTagSet(names)
+	return option{
+		encode: func(e *Encoder) { e.tags = tags },
+		decode: func(d *Decoder) { d.tags = tags },
+	}
+}
+
+// UseTextMarshaler makes Marshal store values implementing
+// encoding.TextMarshaler as a S attribute holding their text, and makes
+// Unmarshal restore them by UnmarshalText. A Marshaler or Unmarshaler
+// implementation takes precedence.
+func UseTextMarshaler() Option {
+	return option{
+		encode: func(e *Encoder) { e.useTextMarshaler = true },
+		decode: func(d *Decoder) { d.useTextMarshaler = true },
+	}
+}
+
+// UseJSONMarshaler makes Marshal store values implementing json.Marshaler
+// as the document their JSON encoding describes, with objects as M, arrays
+// as L and numbers as N, and makes Unmarshal restore them by UnmarshalJSON.
+// Marshaler and, if enabled, encoding.TextMarshaler implementations take
+// precedence.
+func UseJSONMarshaler() Option {
+	return option{
+		encode: func(e *Encoder) { e.useJSONMarshaler = true },
+		decode: func(d *Decoder) { d.useJSONMarshaler = true },
+	}
+}
+
+// An EncodeFunc converts a value of the type it is registered for by
+// TypeEncoder to an attribute value.
+type EncodeFunc func(reflect.Value) (*dynamodb.AttributeValue, error)
+
+// A DecodeFunc stores an attribute value in v, a settable value of the type
+// it is registered for by TypeDecoder.
+type DecodeFunc func(value *dynamodb.AttributeValue, v reflect.Value) error
+
+// TypeEncoder makes Marshal encode values of type t by f, for types which
+// cannot implement Marshaler such as types of other packages. f takes
+// precedence over any method of t and is called for nil values too when t
+// is a pointer or interface type. A nil attribute value returned by f is
+// encoded as NULL; an error is reported as a *MarshalerError. Fields with a
+// set or time format tag option are encoded by that option.
+func TypeEncoder(t reflect.Type, f EncodeFunc) EncodeOption {
+	return encodeOptionFunc(func(e *Encoder) {
+		m := make(map[reflect.Type]EncodeFunc, len(e.typeEncoders)+1)
+		for k, v := range e.typeEncoders {
+			m[k] = v
+		}
+		m[t] = f
+		e.typeEncoders = m
+	})
+}
+
+// TypeDecoder makes Unmarshal decode attribute values into values of type t
+// by f, the counterpart of TypeEncoder. f takes precedence over any method
+// of t and is called for NULL too. Its errors are returned as they are.
+// Fields with a time format tag option are decoded by that option unless
+// the attribute is NULL, matching TypeEncoder.
+func TypeDecoder(t reflect.Type, f DecodeFunc) DecodeOption {
+	return decodeOptionFunc(func(d *Decoder) {
+		m := make(map[reflect.Type]DecodeFunc, len(d.typeDecoders)+1)
+		for k, v := range d.typeDecoders {
+			m[k] = v
+		}
+		m[t] = f
+		d.typeDecoders = m
+	})
+}
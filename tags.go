@@ -0,0 +1,90 @@
+package ddb
+
+import (
+	"reflect"
+	"strings"
+)
+
+// A tagSet is the list of struct tag keys consulted, in order, for
+// attribute names and options.
+type tagSet struct {
+	names []string
+	key   string // names joined by commas, identifies the set in fieldCache
+}
+
+func newTagSet(names []string) *tagSet {
+	return &tagSet{names: names, key: strings.Join(names, ",")}
+}
+
+// defaultTagSet is used when no TagNames option is given. The json tag is
+// read only when configured by TagNames.
+var defaultTagSet = newTagSet([]string{"dynamodb"})
+
+// lookupTag returns the value of the first of names present in the tag of f.
+func lookupTag(f reflect.StructField, names []string) string {
+	for _, name := range names {
+		if tag, ok := f.Tag.Lookup(name); ok {
+			return tag
+		}
+	}
+	return ""
+}
+
+// tagOptions is the comma-separated list of options following the name in
+// a struct tag.
+type tagOptions string
+
+func parseTag(tag string) (string, tagOptions) {
+	if idx := strings.Index(tag, ","); idx != -1 {
+		return tag[:idx], tagOptions(tag[idx+1:])
+	}
+	return tag, ""
+}
+
+// contains reports whether opts includes the option name.
+func (opts tagOptions) contains(name string) bool {
+	s := string(opts)
+	for s != "" {
+		var next string
+		if idx := strings.Index(s, ","); idx != -1 {
+			s, next = s[:idx], s[idx+1:]
+		}
+		if s == name {
+			return true
+		}
+		s = next
+	}
+	return false
+}
+
+// omitEmpty reports whether the field should be skipped when it has an empty
+// value. omitifempty is accepted as an older spelling of omitempty.
+func (opts tagOptions) omitEmpty() bool {
+	return opts.contains("omitempty") || opts.contains("omitifempty")
+}
+
+// setType returns the dynamodb set type requested by the stringset,
+// numberset or binaryset option, or "" if none is given.
+func (opts tagOptions) setType() string {
+	switch {
+	case opts.contains("stringset"):
+		return "SS"
+	case opts.contains("numberset"):
+		return "NS"
+	case opts.contains("binaryset"):
+		return "BS"
+	}
+	return ""
+}
+
+// timeFormat returns the format requested by the unixtime or unixmilli
+// option for time.Time fields, or "" if none is given.
+func (opts tagOptions) timeFormat() string {
+	switch {
+	case opts.contains(timeFormatUnix):
+		return timeFormatUnix
+	case opts.contains(timeFormatUnixMilli):
+		return timeFormatUnixMilli
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package ddb
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// Time formats selected by the unixtime and unixmilli tag options. Without
+// either a time.Time is stored as a S in RFC 3339 format with nanoseconds.
+const (
+	timeFormatUnix      = "unixtime"
+	timeFormatUnixMilli = "unixmilli"
+)
+
+// timeValue dereferences pointers in value and reports whether it holds a
+// time.Time.
+func timeValue(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value, value.Type() == timeType
+}
+
+// isTimeType reports whether t is time.Time or a pointer to it.
+func isTimeType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == timeType
+}
+
+// marshalTimeValue marshals a time.Time to a S in RFC 3339 format, or to a
+// N of seconds or milliseconds since the Unix epoch for the unixtime and
+// unixmilli formats. The N forms can be used as TTL attributes.
+func marshalTimeValue(value reflect.Value, format string) *dynamodb.AttributeValue {
+	t := value.Interface().(time.Time)
+
+	switch format {
+	case timeFormatUnix:
+		return makeNumberAttrValue(strconv.FormatInt(t.Unix(), 10))
+	case timeFormatUnixMilli:
+		ms := t.Unix()*1000 + int64(t.Nanosecond())/int64(time.Millisecond)
+		return makeNumberAttrValue(strconv.FormatInt(ms, 10))
+	}
+
+	return makeStringAttrValue(t.Format(time.RFC3339Nano))
+}
+
+// unmarshalTimeValue stores a S in RFC 3339 format or a N of seconds, or of
+// milliseconds for the unixmilli format, since the Unix epoch in v, which
+// must be a time.Time or a pointer to one.
+func (d *Decoder) unmarshalTimeValue(value *dynamodb.AttributeValue, v reflect.Value, format string, path string) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if value.S != nil {
+		t, err := time.Parse(time.RFC3339Nano, *value.S)
+		if err != nil {
+			return &UnmarshalTimeError{Path: path, Value: *value.S, Err: err}
+		}
+		v.Set(reflect.ValueOf(t))
+	} else if value.N != nil {
+		n, err := d.parseIntAttrValue(value, reflect.TypeOf(int64(0)), path)
+		if err != nil {
+			return err
+		}
+		t := time.Unix(n, 0)
+		if format == timeFormatUnixMilli {
+			t = time.Unix(n/1000, n%1000*int64(time.Millisecond))
+		}
+		v.Set(reflect.ValueOf(t))
+	} else {
+		return d.typeError(value, timeType, path)
+	}
+
+	return nil
+}
+
+// unmarshalDurationValue stores a N of nanoseconds or a S accepted by
+// time.ParseDuration, such as "1h30m", in v.
+func (d *Decoder) unmarshalDurationValue(value *dynamodb.AttributeValue, v reflect.Value, path string) error {
+	if value.S != nil {
+		dur, err := time.ParseDuration(*value.S)
+		if err != nil {
+			return &UnmarshalTimeError{Path: path, Value: *value.S, Err: err}
+		}
+		v.SetInt(int64(dur))
+		return nil
+	}
+
+	n, err := d.parseIntAttrValue(value, durationType, path)
+	if err != nil {
+		return err
+	}
+	v.SetInt(n)
+
+	return nil
+}
+
+// An UnmarshalTimeError describes a S attribute value which cannot be parsed
+// as a time.Time or time.Duration.
+type UnmarshalTimeError struct {
+	Path  string
+	Value string
+	Err   error
+}
+
+func (e *UnmarshalTimeError) Error() string {
+	if e.Path == "" {
+		return "ddb: cannot parse " + strconv.Quote(e.Value) + ": " + e.Err.Error()
+	}
+	return "ddb: cannot parse " + strconv.Quote(e.Value) + " at " + e.Path + ": " + e.Err.Error()
+}
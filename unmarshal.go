@@ -1,36 +1,193 @@
 package ddb
 
 import (
+	"encoding"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
 	"reflect"
 	"runtime"
 	"strconv"
-	"strings"
 
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
-var stringType = reflect.TypeOf("string")
+var (
+	stringType  = reflect.TypeOf("string")
+	float64Type = reflect.TypeOf(float64(0))
+)
+
+// Unmarshaler is the interface implemented by types that can unmarshal a
+// dynamodb attribute value of themselves.
+type Unmarshaler interface {
+	UnmarshalDynamoDBAttributeValue(*dynamodb.AttributeValue) error
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// An UnmarshalTypeError describes an attribute value that was not
+// appropriate for a value of a specific Go type.
+type UnmarshalTypeError struct {
+	Path      string       // path to the attribute, e.g. "orders[3].price"
+	Attribute string       // dynamodb data type of the attribute, e.g. "S"
+	GoType    reflect.Type // type of Go value it could not be assigned to
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	if e.Path == "" {
+		return "ddb: cannot unmarshal " + e.Attribute + " into Go value of type " + e.GoType.String()
+	}
+	return "ddb: cannot unmarshal " + e.Attribute + " into Go value of type " + e.GoType.String() + " at " + e.Path
+}
+
+// An UnmarshalNumberError describes a N attribute value which cannot be
+// represented by the numeric Go type it is unmarshaled into.
+type UnmarshalNumberError struct {
+	Path   string       // path to the attribute, e.g. "orders[3].price"
+	Number string       // the number as stored in dynamodb
+	GoType reflect.Type // type of Go value it could not be assigned to
+	Err    error        // strconv.ErrRange, strconv.ErrSyntax or errNotIntegral
+}
+
+func (e *UnmarshalNumberError) Error() string {
+	if e.Path == "" {
+		return "ddb: cannot unmarshal number " + e.Number + " into Go value of type " + e.GoType.String() + ": " + e.Err.Error()
+	}
+	return "ddb: cannot unmarshal number " + e.Number + " into Go value of type " + e.GoType.String() + " at " + e.Path + ": " + e.Err.Error()
+}
+
+// An UnmarshalLengthError describes a L attribute value with more elements
+// than the Go array it is unmarshaled into.
+type UnmarshalLengthError struct {
+	Path   string       // path to the attribute, e.g. "orders[3].price"
+	Len    int          // number of elements of the list
+	GoType reflect.Type // type of Go array it could not be assigned to
+}
+
+func (e *UnmarshalLengthError) Error() string {
+	if e.Path == "" {
+		return "ddb: cannot unmarshal L of " + strconv.Itoa(e.Len) + " elements into Go value of type " + e.GoType.String()
+	}
+	return "ddb: cannot unmarshal L of " + strconv.Itoa(e.Len) + " elements into Go value of type " + e.GoType.String() + " at " + e.Path
+}
+
+var errNotIntegral = errors.New("value is not an integer")
+
+// A Decoder converts dynamodb attribute values to Go values following the
+// options it is created with. It is safe for concurrent use.
+type Decoder struct {
+	tags             *tagSet
+	strict           bool
+	truncateFloats   bool
+	useTextMarshaler bool
+	useJSONMarshaler bool
+	useNumber        bool
+	preserveOnNull   bool
+	typeDecoders     map[reflect.Type]DecodeFunc
+}
+
+var defaultDecoder = NewDecoder()
+
+// NewDecoder returns a Decoder configured by opts.
+func NewDecoder(opts ...DecodeOption) *Decoder {
+	d := &Decoder{tags: defaultTagSet}
+	for _, opt := range opts {
+		opt.applyDecode(d)
+	}
+	return d
+}
+
+// decoderFor returns the default Decoder, or a new one if opts are given.
+func decoderFor(opts []DecodeOption) *Decoder {
+	if len(opts) == 0 {
+		return defaultDecoder
+	}
+	return NewDecoder(opts...)
+}
 
 // Unmarshal converts dynamodb attribute value map to map or struct
-func Unmarshal(item map[string]*dynamodb.AttributeValue, v interface{}) (err error) {
+func Unmarshal(item map[string]*dynamodb.AttributeValue, v interface{}, opts ...DecodeOption) error {
+	return decoderFor(opts).Decode(item, v)
+}
+
+// Decode converts dynamodb attribute value map to map or struct like
+// Unmarshal with the options of d.
+func (d *Decoder) Decode(item map[string]*dynamodb.AttributeValue, v interface{}) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			if _, ok := r.(runtime.Error); ok {
 				panic(r)
 			}
-			err = r.(error)
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("ddb: %v", r)
+			}
 		}
 	}()
 
-	if reflect.TypeOf(v).Kind() != reflect.Ptr {
-		return errors.New("value must be a pointer")
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("value must be a non-nil pointer")
+	}
+
+	if f, ok := d.typeDecoders[rv.Elem().Type()]; ok {
+		return f(&dynamodb.AttributeValue{M: item}, rv.Elem())
+	}
+
+	if u, ok := v.(Unmarshaler); ok {
+		return u.UnmarshalDynamoDBAttributeValue(&dynamodb.AttributeValue{M: item})
 	}
 
-	return unmarshalItem(item, v)
+	return d.unmarshalItem(item, v, "")
 }
 
-func unmarshalItem(item map[string]*dynamodb.AttributeValue, v interface{}) error {
+// UnmarshalAttributeValue converts a single dynamodb attribute value to the
+// value v points to, following the same rules as fields decoded by Unmarshal.
+func UnmarshalAttributeValue(value *dynamodb.AttributeValue, v interface{}, opts ...DecodeOption) error {
+	return decoderFor(opts).DecodeAttributeValue(value, v)
+}
+
+// DecodeAttributeValue converts a single dynamodb attribute value to the
+// value v points to like UnmarshalAttributeValue with the options of d.
+func (d *Decoder) DecodeAttributeValue(value *dynamodb.AttributeValue, v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(runtime.Error); ok {
+				panic(r)
+			}
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("ddb: %v", r)
+			}
+		}
+	}()
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("value must be a non-nil pointer")
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	return d.unmarshalAttrValue(value, rv.Elem(), "")
+}
+
+func (d *Decoder) unmarshalItem(item map[string]*dynamodb.AttributeValue, v interface{}, path string) error {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		if f, ok := d.typeDecoders[rv.Elem().Type()]; ok {
+			return f(&dynamodb.AttributeValue{M: item}, rv.Elem())
+		}
+	}
+
 	t := reflect.TypeOf(v)
 
 	if t.Kind() == reflect.Ptr {
@@ -53,199 +210,575 @@ func unmarshalItem(item map[string]*dynamodb.AttributeValue, v interface{}) erro
 			dest.Set(reflect.New(t))
 		}
 
-		destType := dest.Elem().Type()
-		numOfField := destType.NumField()
-		for i := 0; i < numOfField; i++ {
-			f := destType.Field(i)
-
-			if f.PkgPath != "" {
+		for _, f := range cachedTypeFields(t, d.tags) {
+			value, ok := item[f.name]
+			if !ok {
 				continue
 			}
 
-			name, option := parseTag(f.Tag.Get("json"))
-			if name == "-" {
+			targetField, ok := fieldByIndexAlloc(dest.Elem(), f.index)
+			if !ok {
 				continue
 			}
-			if option == "omitifempty" {
-				continue
+
+			var err error
+			if isTimeType(f.typ) && f.timeFormat != "" && value.NULL == nil {
+				err = d.unmarshalTimeValue(value, targetField, f.timeFormat, joinPath(path, f.name))
+			} else {
+				err = d.unmarshalAttrValue(value, targetField, joinPath(path, f.name))
 			}
-			if name == "" {
-				name = f.Name
-			}
-
-			if value, ok := item[name]; ok {
-				targetField := dest.Elem().FieldByIndex(f.Index)
-
-				if value.S != nil {
-					if f.Type.Kind() == reflect.String {
-						targetField.SetString(*value.S)
-					}
-				} else if value.BOOL != nil {
-					if f.Type.Kind() == reflect.Bool {
-						targetField.SetBool(*value.BOOL)
-					}
-				} else if value.B != nil {
-					targetField.SetBytes(value.B)
-				} else if value.N != nil {
-					switch f.Type.Kind() {
-					case reflect.Int:
-						targetField.SetInt(parseIntAttrValue(value, 0))
-					case reflect.Int8:
-						targetField.SetInt(parseIntAttrValue(value, 8))
-					case reflect.Int16:
-						targetField.SetInt(parseIntAttrValue(value, 16))
-					case reflect.Int32:
-						targetField.SetInt(parseIntAttrValue(value, 32))
-					case reflect.Int64:
-						targetField.SetInt(parseIntAttrValue(value, 64))
-					case reflect.Uint:
-						targetField.SetUint(parseUintAttrValue(value, 0))
-					case reflect.Uint8:
-						targetField.SetUint(parseUintAttrValue(value, 8))
-					case reflect.Uint16:
-						targetField.SetUint(parseUintAttrValue(value, 16))
-					case reflect.Uint32:
-						targetField.SetUint(parseUintAttrValue(value, 32))
-					case reflect.Uint64:
-						targetField.SetUint(parseUintAttrValue(value, 64))
-					case reflect.Float32:
-						targetField.SetFloat(parseFloatAttrValue(value, 32))
-					case reflect.Float64:
-						targetField.SetFloat(parseFloatAttrValue(value, 64))
-					}
-				} else if value.SS != nil {
-					length := len(value.SS)
-					arr := reflect.MakeSlice(reflect.SliceOf(stringType), length, length)
-					for i, s := range value.SS {
-						arr.Index(i).SetString(*s)
-					}
-					targetField.Set(arr)
-				} else if value.NS != nil {
-					length := len(value.NS)
-					numberType := reflect.TypeOf(0)
-					arr := reflect.MakeSlice(reflect.SliceOf(numberType), length, length)
-					for i, s := range value.NS {
-						n, _ := strconv.Atoi(*s)
-						arr.Index(i).SetInt(int64(n))
-					}
-					targetField.Set(arr)
-				} else if value.BS != nil {
-					length := len(value.BS)
-					arr := reflect.MakeSlice(reflect.SliceOf(typeOfBytes), length, length)
-					for i, bs := range value.BS {
-						arr.Index(i).SetBytes(bs)
-					}
-					targetField.Set(arr)
-				} else if value.L != nil {
-					length := len(value.L)
-					elementType := f.Type.Elem()
-					arr := reflect.MakeSlice(reflect.SliceOf(elementType), length, length)
-					for i, l := range value.L {
-						m, err := parseMapAttrValue(l, elementType)
-						if err != nil {
-							return err
-						}
-						arr.Index(i).Set(*m)
-					}
-					targetField.Set(arr)
-				} else if value.M != nil {
-					m, err := parseMapAttrValue(value, f.Type)
-					if err != nil {
-						return err
-					}
-					targetField.Set(*m)
-				}
+			if err != nil {
+				return err
 			}
 		}
+	} else if t.Kind() == reflect.Interface && !isptr {
+		return d.unmarshalInterfaceValue(&dynamodb.AttributeValue{M: item}, reflect.ValueOf(v).Elem(), path)
 	} else if t.Kind() == reflect.Map {
-		// TODO implement map
+		dest := reflect.ValueOf(v).Elem()
+		if isptr {
+			if dest.IsNil() {
+				dest.Set(reflect.New(t))
+			}
+			dest = dest.Elem()
+		}
+
+		m, err := d.parseMapValue(item, t, path)
+		if m == nil || err != nil {
+			return err
+		}
+
+		if dest.IsNil() {
+			dest.Set(*m)
+		} else {
+			for _, k := range m.MapKeys() {
+				dest.SetMapIndex(k, m.MapIndex(k))
+			}
+		}
+	} else {
+		return d.typeError(&dynamodb.AttributeValue{M: item}, t, path)
+	}
+
+	return nil
+}
+
+func (d *Decoder) unmarshalAttrValue(value *dynamodb.AttributeValue, targetField reflect.Value, path string) error {
+	if f, ok := d.typeDecoders[targetField.Type()]; ok {
+		return f(value, targetField)
+	}
+
+	if ok, err := d.unmarshalCustomValue(value, targetField, path); ok {
+		return err
+	}
+
+	if value.NULL != nil {
+		d.unmarshalNullValue(targetField)
+		return nil
+	}
+
+	t := targetField.Type()
+
+	if t.Kind() == reflect.Interface {
+		return d.unmarshalInterfaceValue(value, targetField, path)
+	}
+
+	if t.Kind() == reflect.Ptr {
+		if targetField.IsNil() {
+			targetField.Set(reflect.New(t.Elem()))
+		}
+		return d.unmarshalAttrValue(value, targetField.Elem(), path)
+	}
+
+	switch t {
+	case timeType:
+		return d.unmarshalTimeValue(value, targetField, "", path)
+	case durationType:
+		if value.S != nil || value.N != nil {
+			return d.unmarshalDurationValue(value, targetField, path)
+		}
+	}
+
+	if (value.SS != nil || value.NS != nil || value.BS != nil) && t.Kind() == reflect.Map && mapSetType(t) != "" {
+		return d.unmarshalSetMap(value, targetField, path)
+	}
+
+	if value.S != nil {
+		if t.Kind() != reflect.String {
+			return d.typeError(value, t, path)
+		}
+		targetField.SetString(*value.S)
+	} else if value.BOOL != nil {
+		if t.Kind() != reflect.Bool {
+			return d.typeError(value, t, path)
+		}
+		targetField.SetBool(*value.BOOL)
+	} else if value.B != nil {
+		if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
+			return d.typeError(value, t, path)
+		}
+		targetField.SetBytes(value.B)
+	} else if value.N != nil {
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := d.parseIntAttrValue(value, t, path)
+			if err != nil {
+				return err
+			}
+			targetField.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := d.parseUintAttrValue(value, t, path)
+			if err != nil {
+				return err
+			}
+			targetField.SetUint(n)
+		case reflect.Float32, reflect.Float64:
+			f, err := parseFloatAttrValue(value, t, path)
+			if err != nil {
+				return err
+			}
+			targetField.SetFloat(f)
+		case reflect.String:
+			if t != numberType {
+				return d.typeError(value, t, path)
+			}
+			targetField.SetString(*value.N)
+		default:
+			return d.typeError(value, t, path)
+		}
+	} else if value.SS != nil {
+		if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.String {
+			return d.typeError(value, t, path)
+		}
+		length := len(value.SS)
+		arr := reflect.MakeSlice(t, length, length)
+		for i, s := range value.SS {
+			arr.Index(i).SetString(*s)
+		}
+		targetField.Set(arr)
+	} else if value.NS != nil {
+		if t.Kind() != reflect.Slice || !isNumberSetElem(t.Elem()) {
+			return d.typeError(value, t, path)
+		}
+		length := len(value.NS)
+		arr := reflect.MakeSlice(t, length, length)
+		for i, s := range value.NS {
+			elem := arr.Index(i)
+			for elem.Kind() == reflect.Ptr {
+				elem.Set(reflect.New(elem.Type().Elem()))
+				elem = elem.Elem()
+			}
+			if elem.Kind() == reflect.String {
+				elem.SetString(*s)
+				continue
+			}
+			if err := d.unmarshalAttrValue(&dynamodb.AttributeValue{N: s}, elem, indexPath(path, i)); err != nil {
+				return err
+			}
+		}
+		targetField.Set(arr)
+	} else if value.BS != nil {
+		if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Slice || t.Elem().Elem().Kind() != reflect.Uint8 {
+			return d.typeError(value, t, path)
+		}
+		length := len(value.BS)
+		arr := reflect.MakeSlice(t, length, length)
+		for i, bs := range value.BS {
+			arr.Index(i).SetBytes(bs)
+		}
+		targetField.Set(arr)
+	} else if value.L != nil {
+		return d.unmarshalListValue(value, targetField, path)
+	} else if value.M != nil {
+		m, err := d.parseMapAttrValue(value, t, path)
+		if err != nil {
+			return err
+		}
+		if m != nil {
+			targetField.Set(*m)
+		}
 	}
 
 	return nil
 }
 
-func parseIntAttrValue(value *dynamodb.AttributeValue, bitSize int) int64 {
-	n, _ := strconv.ParseInt(*value.N, 10, bitSize)
-	return n
+// unmarshalNullValue sets v to its zero value, which is nil for pointers,
+// maps, slices and interfaces, unless PreserveOnNull is set.
+func (d *Decoder) unmarshalNullValue(v reflect.Value) {
+	if !d.preserveOnNull {
+		v.Set(reflect.Zero(v.Type()))
+	}
 }
 
-func parseUintAttrValue(value *dynamodb.AttributeValue, bitSize int) uint64 {
-	n, _ := strconv.ParseUint(*value.N, 10, bitSize)
-	return n
+// unmarshalListValue unmarshals the elements of a L into a new slice, or
+// into an array whose elements beyond the list are zeroed.
+func (d *Decoder) unmarshalListValue(value *dynamodb.AttributeValue, targetField reflect.Value, path string) error {
+	t := targetField.Type()
+
+	var arr reflect.Value
+	switch t.Kind() {
+	case reflect.Slice:
+		arr = reflect.MakeSlice(t, len(value.L), len(value.L))
+	case reflect.Array:
+		if len(value.L) > t.Len() {
+			return &UnmarshalLengthError{Path: path, Len: len(value.L), GoType: t}
+		}
+		arr = reflect.New(t).Elem()
+	default:
+		return d.typeError(value, t, path)
+	}
+
+	for i, l := range value.L {
+		if err := d.unmarshalAttrValue(l, arr.Index(i), indexPath(path, i)); err != nil {
+			return err
+		}
+	}
+	targetField.Set(arr)
+
+	return nil
 }
 
-func parseFloatAttrValue(value *dynamodb.AttributeValue, bitSize int) float64 {
-	f, _ := strconv.ParseFloat(*value.N, bitSize)
-	return f
+// unmarshalCustomValue unmarshals value by the Unmarshaler implemented by v
+// or, if enabled, by its encoding.TextUnmarshaler or json.Unmarshaler. It
+// reports false if v is to be unmarshaled by its kind.
+func (d *Decoder) unmarshalCustomValue(value *dynamodb.AttributeValue, v reflect.Value, path string) (bool, error) {
+	if u, ok := indirectImplementer(v, unmarshalerType); ok {
+		return true, u.(Unmarshaler).UnmarshalDynamoDBAttributeValue(value)
+	}
+
+	if d.useTextMarshaler && value.S != nil {
+		if u, ok := indirectImplementer(v, textUnmarshalerType); ok {
+			return true, u.(encoding.TextUnmarshaler).UnmarshalText([]byte(*value.S))
+		}
+	}
+
+	if d.useJSONMarshaler {
+		if u, ok := indirectImplementer(v, jsonUnmarshalerType); ok {
+			b, err := attrValueToJSON(value)
+			if err != nil {
+				return true, err
+			}
+			return true, u.(json.Unmarshaler).UnmarshalJSON(b)
+		}
+	}
+
+	return false, nil
 }
 
-func parseMapAttrValue(value *dynamodb.AttributeValue, t reflect.Type) (*reflect.Value, error) {
-	if t.Kind() == reflect.Ptr {
+// indirectImplementer returns v as the interface type iface if v implements
+// it, allocating v if it is a nil pointer, or if v is addressable and a
+// pointer to it implements iface.
+func indirectImplementer(v reflect.Value, iface reflect.Type) (interface{}, bool) {
+	if v.Kind() == reflect.Ptr && v.Type().Implements(iface) {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return v.Interface(), true
+	}
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(iface) {
+		return v.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+// unmarshalSetMap stores the members of a SS, NS or BS as the keys of a map
+// with struct{} or bool values.
+func (d *Decoder) unmarshalSetMap(value *dynamodb.AttributeValue, targetField reflect.Value, path string) error {
+	t := targetField.Type()
+
+	var elems []*dynamodb.AttributeValue
+	for _, s := range value.SS {
+		elems = append(elems, &dynamodb.AttributeValue{S: s})
+	}
+	for _, n := range value.NS {
+		elems = append(elems, &dynamodb.AttributeValue{N: n})
+	}
+	for _, b := range value.BS {
+		elems = append(elems, &dynamodb.AttributeValue{B: b})
+	}
+
+	member := reflect.Zero(t.Elem())
+	if t.Elem().Kind() == reflect.Bool {
+		member = reflect.ValueOf(true).Convert(t.Elem())
+	}
+
+	m := reflect.MakeMap(t)
+	for i, elem := range elems {
+		key := reflect.New(t.Key()).Elem()
+		if err := d.unmarshalAttrValue(elem, key, indexPath(path, i)); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, member)
+	}
+	targetField.Set(m)
+
+	return nil
+}
+
+// isNumberSetElem reports whether a NS member can be stored in a value of
+// type t. Strings hold the number exactly as stored in dynamodb.
+func isNumberSetElem(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
-	if t.Kind() == reflect.Struct {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// typeError reports that value cannot be stored in a Go value of type t.
+// Outside of strict mode the value is skipped and nil is returned.
+func (d *Decoder) typeError(value *dynamodb.AttributeValue, t reflect.Type, path string) error {
+	if !d.strict {
+		return nil
+	}
+
+	return &UnmarshalTypeError{Path: path, Attribute: attrType(value), GoType: t}
+}
+
+// attrType returns the dynamodb data type name of value.
+func attrType(value *dynamodb.AttributeValue) string {
+	switch {
+	case value.S != nil:
+		return "S"
+	case value.N != nil:
+		return "N"
+	case value.B != nil:
+		return "B"
+	case value.BOOL != nil:
+		return "BOOL"
+	case value.NULL != nil:
+		return "NULL"
+	case value.SS != nil:
+		return "SS"
+	case value.NS != nil:
+		return "NS"
+	case value.BS != nil:
+		return "BS"
+	case value.L != nil:
+		return "L"
+	case value.M != nil:
+		return "M"
+	}
+
+	return "unknown"
+}
+
+func (d *Decoder) parseIntAttrValue(value *dynamodb.AttributeValue, t reflect.Type, path string) (int64, error) {
+	n, err := strconv.ParseInt(*value.N, 10, t.Bits())
+	if err == nil {
+		return n, nil
+	}
+	if err.(*strconv.NumError).Err == strconv.ErrRange {
+		return 0, &UnmarshalNumberError{Path: path, Number: *value.N, GoType: t, Err: strconv.ErrRange}
+	}
+
+	i, err := d.parseIntegralNumber(value, t, path)
+	if err != nil {
+		return 0, err
+	}
+	bits := uint(t.Bits())
+	min := new(big.Int).Lsh(big.NewInt(-1), bits-1)
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits-1), big.NewInt(1))
+	if i.Cmp(min) < 0 || i.Cmp(max) > 0 {
+		return 0, &UnmarshalNumberError{Path: path, Number: *value.N, GoType: t, Err: strconv.ErrRange}
+	}
+
+	return i.Int64(), nil
+}
+
+func (d *Decoder) parseUintAttrValue(value *dynamodb.AttributeValue, t reflect.Type, path string) (uint64, error) {
+	n, err := strconv.ParseUint(*value.N, 10, t.Bits())
+	if err == nil {
+		return n, nil
+	}
+	if err.(*strconv.NumError).Err == strconv.ErrRange {
+		return 0, &UnmarshalNumberError{Path: path, Number: *value.N, GoType: t, Err: strconv.ErrRange}
+	}
+
+	i, err := d.parseIntegralNumber(value, t, path)
+	if err != nil {
+		return 0, err
+	}
+	if i.Sign() < 0 || i.BitLen() > t.Bits() {
+		return 0, &UnmarshalNumberError{Path: path, Number: *value.N, GoType: t, Err: strconv.ErrRange}
+	}
+
+	return i.Uint64(), nil
+}
+
+// parseIntegralNumber parses a number which strconv does not accept as an
+// integer, such as "1.0", "1e3" or "-0". Numbers with a fractional part are
+// rejected unless TruncateFloats is set, in which case they are truncated
+// toward zero.
+func (d *Decoder) parseIntegralNumber(value *dynamodb.AttributeValue, t reflect.Type, path string) (*big.Int, error) {
+	f, _, err := big.ParseFloat(*value.N, 10, 1024, big.ToZero)
+	if err != nil {
+		return nil, &UnmarshalNumberError{Path: path, Number: *value.N, GoType: t, Err: strconv.ErrSyntax}
+	}
+
+	i, acc := f.Int(nil)
+	if acc != big.Exact && !d.truncateFloats {
+		return nil, &UnmarshalNumberError{Path: path, Number: *value.N, GoType: t, Err: errNotIntegral}
+	}
+
+	return i, nil
+}
+
+func parseFloatAttrValue(value *dynamodb.AttributeValue, t reflect.Type, path string) (float64, error) {
+	f, err := strconv.ParseFloat(*value.N, t.Bits())
+	if err != nil {
+		return 0, &UnmarshalNumberError{Path: path, Number: *value.N, GoType: t, Err: err.(*strconv.NumError).Err}
+	}
+
+	return f, nil
+}
+
+// parseMapAttrValue converts a M value into a new value of type t. It
+// returns a nil value without error when value is not a M outside of strict
+// mode.
+func (d *Decoder) parseMapAttrValue(value *dynamodb.AttributeValue, t reflect.Type, path string) (*reflect.Value, error) {
+	if value.M == nil {
+		return nil, d.typeError(value, t, path)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := d.parseMapAttrValue(value, t.Elem(), path)
+		if elem == nil || err != nil {
+			return nil, err
+		}
+		dest := reflect.New(t.Elem())
+		dest.Elem().Set(*elem)
+		return &dest, nil
+	case reflect.Struct:
 		dest := reflect.New(t)
-		if err := unmarshalItem(value.M, dest.Interface()); err != nil {
+		if err := d.unmarshalItem(value.M, dest.Interface(), path); err != nil {
 			return nil, err
 		}
+		dest = dest.Elem()
 		return &dest, nil
-	} else if t.Kind() == reflect.Map {
-		return parseMapValue(value.M, t)
+	case reflect.Map:
+		return d.parseMapValue(value.M, t, path)
 	}
 
-	return nil, errors.New("unknown err")
+	return nil, d.typeError(value, t, path)
 }
 
-func parseNumber(v string) interface{} {
-	index := strings.Index(v, ".")
+func (d *Decoder) parseMapValue(value map[string]*dynamodb.AttributeValue, typ reflect.Type, path string) (*reflect.Value, error) {
+	if typ.Key().Kind() != reflect.String {
+		return nil, d.typeError(&dynamodb.AttributeValue{M: value}, typ, path)
+	}
 
-	if index > -1 {
-		// number is float
-		value, _ := strconv.ParseFloat(v, 64)
-		return value
+	dest := reflect.MakeMap(typ)
+	elemType := typ.Elem()
+
+	for k, v := range value {
+		elem, err := d.parseElemValue(v, elemType, joinPath(path, k))
+		if err != nil {
+			return nil, err
+		}
+		dest.SetMapIndex(reflect.ValueOf(k).Convert(typ.Key()), *elem)
 	}
 
-	n, _ := strconv.ParseInt(v, 10, 64)
-	if n >= -2147483648 || n <= 2147483647 {
-		return int(n)
+	return &dest, nil
+}
+
+func (d *Decoder) parseElemValue(value *dynamodb.AttributeValue, t reflect.Type, path string) (*reflect.Value, error) {
+	dest := reflect.New(t).Elem()
+	if err := d.unmarshalAttrValue(value, dest, path); err != nil {
+		return nil, err
 	}
 
-	return n
+	return &dest, nil
 }
 
-func parseMapValue(value map[string]*dynamodb.AttributeValue, typ reflect.Type) (*reflect.Value, error) {
-	dest := reflect.MakeMap(typ)
+// unmarshalInterfaceValue stores the Go value interfaceValue returns for
+// value in an empty interface. Other interfaces cannot hold it.
+func (d *Decoder) unmarshalInterfaceValue(value *dynamodb.AttributeValue, targetField reflect.Value, path string) error {
+	t := targetField.Type()
+	if t.NumMethod() != 0 {
+		return d.typeError(value, t, path)
+	}
 
-	for k, v := range value {
-		if v.S != nil {
-			dest.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(*v.S))
-		} else if v.N != nil {
-			dest.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(parseNumber(*v.N)))
-		} else if v.SS != nil {
-			length := len(v.SS)
-			arr := make([]string, length, length)
-			for i, s := range v.SS {
-				arr[i] = *s
-			}
-			dest.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(arr))
-		} else if v.NS != nil {
-			length := len(v.NS)
-			arr := make([]interface{}, length, length)
-			for i, s := range v.NS {
-				arr[i] = parseNumber(*s)
-			}
-			dest.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(arr))
-		} else if v.BS != nil {
-			dest.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v.BS))
-		} else if v.M != nil {
-			v, err := parseMapAttrValue(v, typ)
+	iv, err := d.interfaceValue(value, path)
+	if err != nil {
+		return err
+	}
+	if iv == nil {
+		targetField.Set(reflect.Zero(t))
+		return nil
+	}
+	targetField.Set(reflect.ValueOf(iv))
+
+	return nil
+}
+
+// interfaceValue converts value to the Go value an interface{} holds for it,
+// much as encoding/json does: string for S, float64 for N, or json.Number
+// with UseNumber, bool for BOOL, []byte for B, []string for SS, [][]byte for
+// BS, []interface{} for L and the members of NS, map[string]interface{} for M
+// and nil for NULL.
+func (d *Decoder) interfaceValue(value *dynamodb.AttributeValue, path string) (interface{}, error) {
+	switch {
+	case value.S != nil:
+		return *value.S, nil
+	case value.N != nil:
+		return d.interfaceNumber(value.N, path)
+	case value.BOOL != nil:
+		return *value.BOOL, nil
+	case value.B != nil:
+		return value.B, nil
+	case value.SS != nil:
+		arr := make([]string, len(value.SS))
+		for i, s := range value.SS {
+			arr[i] = *s
+		}
+		return arr, nil
+	case value.NS != nil:
+		arr := make([]interface{}, len(value.NS))
+		for i, n := range value.NS {
+			v, err := d.interfaceNumber(n, indexPath(path, i))
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+		}
+		return arr, nil
+	case value.BS != nil:
+		return value.BS, nil
+	case value.L != nil:
+		arr := make([]interface{}, len(value.L))
+		for i, l := range value.L {
+			v, err := d.interfaceValue(l, indexPath(path, i))
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+		}
+		return arr, nil
+	case value.M != nil:
+		m := make(map[string]interface{}, len(value.M))
+		for k, mv := range value.M {
+			v, err := d.interfaceValue(mv, joinPath(path, k))
 			if err != nil {
 				return nil, err
 			}
-			dest.SetMapIndex(reflect.ValueOf(k), *v)
+			m[k] = v
 		}
+		return m, nil
 	}
 
-	return &dest, nil
+	return nil, nil
+}
+
+func (d *Decoder) interfaceNumber(n *string, path string) (interface{}, error) {
+	if d.useNumber {
+		return json.Number(*n), nil
+	}
+
+	return parseFloatAttrValue(&dynamodb.AttributeValue{N: n}, float64Type, path)
 }
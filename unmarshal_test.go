@@ -1,9 +1,15 @@
 package ddb_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"math/big"
+	"net"
+	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -156,7 +162,7 @@ var _ = Describe("Unmarshal", func() {
 
 			v, ok := sut.Map["map_int"]
 			Expect(ok).Should(BeTrue())
-			Expect(v).To(Equal(54321))
+			Expect(v).To(Equal(float64(54321)))
 		})
 
 		It("should be map which has `map_long` column", func() {
@@ -164,7 +170,7 @@ var _ = Describe("Unmarshal", func() {
 
 			v, ok := sut.Map["map_long"]
 			Expect(ok).Should(BeTrue())
-			Expect(v).To(Equal(1223362036844775800))
+			Expect(v).To(Equal(float64(1223362036844775800)))
 		})
 
 		It("should be map which has `map_float` column", func() {
@@ -198,9 +204,9 @@ var _ = Describe("Unmarshal", func() {
 			vv := v.([]interface{})
 
 			Expect(vv).Should(HaveLen(3))
-			Expect(vv[0]).To(Equal(1))
-			Expect(vv[1]).To(Equal(2))
-			Expect(vv[2]).To(Equal(3))
+			Expect(vv[0]).To(Equal(float64(1)))
+			Expect(vv[1]).To(Equal(float64(2)))
+			Expect(vv[2]).To(Equal(float64(3)))
 		})
 
 		It("should be map which has `map_bs` column", func() {
@@ -217,24 +223,24 @@ var _ = Describe("Unmarshal", func() {
 			Expect(vv[2]).To(Equal([]byte{0x3, 0x3, 0x3}))
 		})
 
-		// It("should be map which has `map_list` column", func() {
-		// 	Expect(sut.Map).ShouldNot(BeNil())
+		It("should be map which has `map_list` column", func() {
+			Expect(sut.Map).ShouldNot(BeNil())
 
-		// 	v, ok := sut.Map["map_list"]
-		// 	Expect(ok).Should(BeTrue())
+			v, ok := sut.Map["map_list"]
+			Expect(ok).Should(BeTrue())
 
-		// 	vv := v.([]interface{})
+			vv := v.([]interface{})
 
-		// 	Expect(vv).Should(HaveLen(3))
-		// 	Expect(vv[0]).To(Equal("a"))
-		// 	Expect(vv[1]).To(Equal(1))
+			Expect(vv).Should(HaveLen(3))
+			Expect(vv[0]).To(Equal("a"))
+			Expect(vv[1]).To(Equal(float64(1)))
 
-		// 	vvv := vv[2].(map[string]interface{})
+			vvv := vv[2].(map[string]interface{})
 
-		// 	v1, ok1 := vvv["hoge"]
-		// 	Expect(ok1).Should(BeTrue())
-		// 	Expect(v1).To(Equal("fuga"))
-		// })
+			v1, ok1 := vvv["hoge"]
+			Expect(ok1).Should(BeTrue())
+			Expect(v1).To(Equal("fuga"))
+		})
 
 		It("should be map which has `map_map` column", func() {
 			Expect(sut.Map).ShouldNot(BeNil())
@@ -252,15 +258,15 @@ var _ = Describe("Unmarshal", func() {
 
 	Context("unmarshal struct map", func() {
 		type TestB struct {
-			TestC map[string]interface{} `json:"test_c"`
+			TestC map[string]interface{} `dynamodb:"test_c"`
 		}
 
 		type TestA struct {
-			TestB *TestB `json:"test_b"`
+			TestB *TestB `dynamodb:"test_b"`
 		}
 
 		type Test struct {
-			TestA *TestA `json:"test_a"`
+			TestA *TestA `dynamodb:"test_a"`
 		}
 
 		var sut Test
@@ -303,4 +309,1056 @@ var _ = Describe("Unmarshal", func() {
 			Expect(v2).To(Equal("fuga"))
 		})
 	})
+
+	Context("unmarshal into map", func() {
+		var d map[string]*dynamodb.AttributeValue
+
+		BeforeEach(func() {
+			d = map[string]*dynamodb.AttributeValue{
+				"str":  &dynamodb.AttributeValue{S: aws.String("foo")},
+				"num":  &dynamodb.AttributeValue{N: aws.String("42")},
+				"bool": &dynamodb.AttributeValue{BOOL: aws.Bool(true)},
+				"blob": &dynamodb.AttributeValue{B: []byte{0x1, 0x2}},
+				"null": &dynamodb.AttributeValue{NULL: aws.Bool(true)},
+				"list": &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+					&dynamodb.AttributeValue{S: aws.String("a")},
+					&dynamodb.AttributeValue{BOOL: aws.Bool(false)},
+				}},
+			}
+		})
+
+		It("should fill map[string]interface{}", func() {
+			var sut map[string]interface{}
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+
+			Expect(sut).Should(HaveLen(6))
+			Expect(sut["str"]).To(Equal("foo"))
+			Expect(sut["num"]).To(Equal(float64(42)))
+			Expect(sut["bool"]).To(Equal(true))
+			Expect(sut["blob"]).To(Equal([]byte{0x1, 0x2}))
+			Expect(sut).Should(HaveKey("null"))
+			Expect(sut["null"]).To(BeNil())
+			Expect(sut["list"]).To(Equal([]interface{}{"a", false}))
+		})
+
+		It("should keep existing entries of non-nil map", func() {
+			sut := map[string]interface{}{"existing": "value"}
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+
+			Expect(sut["existing"]).To(Equal("value"))
+			Expect(sut["str"]).To(Equal("foo"))
+		})
+
+		It("should fill map[string]string", func() {
+			var sut map[string]string
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"a": &dynamodb.AttributeValue{S: aws.String("foo")},
+				"b": &dynamodb.AttributeValue{S: aws.String("bar")},
+			}, &sut)).To(Succeed())
+
+			Expect(sut).To(Equal(map[string]string{"a": "foo", "b": "bar"}))
+		})
+
+		It("should fill map[string]struct", func() {
+			var sut map[string]child
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"a": &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+					"content": &dynamodb.AttributeValue{S: aws.String("foo")},
+				}},
+			}, &sut)).To(Succeed())
+
+			Expect(sut).Should(HaveKey("a"))
+			Expect(sut["a"].Content).To(Equal("foo"))
+		})
+
+		It("should fill map[string]*struct", func() {
+			var sut map[string]*child
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"a": &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+					"content": &dynamodb.AttributeValue{S: aws.String("foo")},
+				}},
+			}, &sut)).To(Succeed())
+
+			Expect(sut["a"]).NotTo(BeNil())
+			Expect(sut["a"].Content).To(Equal("foo"))
+		})
+	})
+
+	Context("unmarshal into interface{}", func() {
+		type doc struct {
+			S    interface{}   `dynamodb:"s"`
+			N    interface{}   `dynamodb:"n"`
+			Bool interface{}   `dynamodb:"bool"`
+			B    interface{}   `dynamodb:"b"`
+			Null interface{}   `dynamodb:"null"`
+			Sets interface{}   `dynamodb:"sets"`
+			Deep interface{}   `dynamodb:"deep"`
+			List []interface{} `dynamodb:"list"`
+		}
+
+		var d map[string]*dynamodb.AttributeValue
+
+		BeforeEach(func() {
+			d = map[string]*dynamodb.AttributeValue{
+				"s":    &dynamodb.AttributeValue{S: aws.String("foo")},
+				"n":    &dynamodb.AttributeValue{N: aws.String("1.5")},
+				"bool": &dynamodb.AttributeValue{BOOL: aws.Bool(true)},
+				"b":    &dynamodb.AttributeValue{B: []byte{0x1}},
+				"null": &dynamodb.AttributeValue{NULL: aws.Bool(true)},
+				"sets": &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+					"ss": &dynamodb.AttributeValue{SS: aws.StringSlice([]string{"a"})},
+					"ns": &dynamodb.AttributeValue{NS: aws.StringSlice([]string{"2"})},
+					"bs": &dynamodb.AttributeValue{BS: [][]byte{[]byte{0x2}}},
+				}},
+				"deep": &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+					&dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+						"n": &dynamodb.AttributeValue{N: aws.String("12345678901234567890")},
+						"l": &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+							&dynamodb.AttributeValue{NULL: aws.Bool(true)},
+						}},
+					}},
+				}},
+				"list": &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+					&dynamodb.AttributeValue{S: aws.String("a")},
+					&dynamodb.AttributeValue{N: aws.String("3")},
+				}},
+			}
+		})
+
+		It("should store natural Go values at any depth", func() {
+			sut := doc{Null: "previous"}
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+
+			Expect(sut).To(Equal(doc{
+				S:    "foo",
+				N:    1.5,
+				Bool: true,
+				B:    []byte{0x1},
+				Null: nil,
+				Sets: map[string]interface{}{
+					"ss": []string{"a"},
+					"ns": []interface{}{float64(2)},
+					"bs": [][]byte{[]byte{0x2}},
+				},
+				Deep: []interface{}{
+					map[string]interface{}{
+						"n": float64(12345678901234567890),
+						"l": []interface{}{nil},
+					},
+				},
+				List: []interface{}{"a", float64(3)},
+			}))
+		})
+
+		It("should store numbers as json.Number with UseNumber", func() {
+			var sut doc
+			Expect(Unmarshal(d, &sut, UseNumber())).To(Succeed())
+
+			Expect(sut.N).To(Equal(json.Number("1.5")))
+			Expect(sut.List[1]).To(Equal(json.Number("3")))
+			deep := sut.Deep.([]interface{})[0].(map[string]interface{})
+			Expect(deep["n"]).To(Equal(json.Number("12345678901234567890")))
+			Expect(sut.Sets.(map[string]interface{})["ns"]).To(Equal([]interface{}{json.Number("2")}))
+		})
+
+		It("should fill *interface{}", func() {
+			var sut interface{}
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+
+			m, ok := sut.(map[string]interface{})
+			Expect(ok).To(BeTrue())
+			Expect(m).Should(HaveLen(8))
+			Expect(m["s"]).To(Equal("foo"))
+			Expect(m["list"]).To(Equal([]interface{}{"a", float64(3)}))
+		})
+
+		It("should fill *interface{} from an attribute value", func() {
+			var sut interface{}
+			Expect(UnmarshalAttributeValue(d["deep"], &sut, UseNumber())).To(Succeed())
+			Expect(sut).To(Equal([]interface{}{
+				map[string]interface{}{
+					"n": json.Number("12345678901234567890"),
+					"l": []interface{}{nil},
+				},
+			}))
+		})
+
+		It("should report numbers out of range with path", func() {
+			var sut doc
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"deep": &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+					&dynamodb.AttributeValue{NS: aws.StringSlice([]string{"1", "1e400"})},
+				}},
+			}, &sut)
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalNumberError{}))
+			Expect(err.(*UnmarshalNumberError).Path).To(Equal("deep[0][1]"))
+		})
+
+		It("should not store values in non-empty interfaces", func() {
+			var sut struct {
+				S fmt.Stringer `dynamodb:"s"`
+			}
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+			Expect(sut.S).To(BeNil())
+
+			err := Unmarshal(d, &sut, Strict())
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+		})
+
+		It("should round trip json.Number", func() {
+			var sut map[string]interface{}
+			Expect(Unmarshal(d, &sut, UseNumber())).To(Succeed())
+
+			item, err := MarshalE(sut)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(item["n"]).To(Equal(&dynamodb.AttributeValue{N: aws.String("1.5")}))
+			Expect(item["deep"]).To(Equal(d["deep"]))
+		})
+
+		It("should fill json.Number fields from N", func() {
+			var sut struct {
+				N json.Number `dynamodb:"n"`
+			}
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+			Expect(sut.N).To(Equal(json.Number("1.5")))
+		})
+	})
+
+	Context("unmarshal list", func() {
+		n := func(s string) *dynamodb.AttributeValue { return &dynamodb.AttributeValue{N: aws.String(s)} }
+		str := func(s string) *dynamodb.AttributeValue { return &dynamodb.AttributeValue{S: aws.String(s)} }
+		null := &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+		list := func(l ...*dynamodb.AttributeValue) *dynamodb.AttributeValue { return &dynamodb.AttributeValue{L: l} }
+
+		type lists struct {
+			Strings []string     `dynamodb:"strings"`
+			Ints    []int        `dynamodb:"ints"`
+			Ptrs    []*int       `dynamodb:"ptrs"`
+			Nested  [][]string   `dynamodb:"nested"`
+			Times   []time.Time  `dynamodb:"times"`
+			Arr     [3]int       `dynamodb:"arr"`
+			Arrs    [][2]float64 `dynamodb:"arrs"`
+		}
+
+		It("should restore lists marshaled from slices and arrays", func() {
+			s := sample{
+				Arr:        [3]int{1, 2, 3},
+				Slice:      []string{"f", "o", "o"},
+				EmptySlice: []int{},
+			}
+
+			var sut sample
+			Expect(Unmarshal(Marshal(s), &sut)).To(Succeed())
+			Expect(sut.Arr).To(Equal(s.Arr))
+			Expect(sut.Slice).To(Equal(s.Slice))
+			Expect(sut.EmptySlice).To(Equal(s.EmptySlice))
+		})
+
+		It("should decode each element by its type", func() {
+			var sut lists
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"strings": list(str("a"), str("b")),
+				"ints":    list(n("1"), n("-2")),
+				"ptrs":    list(n("1"), null),
+				"nested":  list(list(str("a")), &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}}, &dynamodb.AttributeValue{SS: aws.StringSlice([]string{"b"})}),
+				"times":   list(str("2017-01-02T03:04:05Z")),
+				"arrs":    list(list(n("1.5")), list(n("2"), n("3"))),
+			}, &sut, Strict())).To(Succeed())
+
+			one := 1
+			Expect(sut.Strings).To(Equal([]string{"a", "b"}))
+			Expect(sut.Ints).To(Equal([]int{1, -2}))
+			Expect(sut.Ptrs).To(Equal([]*int{&one, nil}))
+			Expect(sut.Nested).To(Equal([][]string{{"a"}, {}, {"b"}}))
+			Expect(sut.Times).To(HaveLen(1))
+			Expect(sut.Times[0].Equal(time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC))).To(BeTrue())
+			Expect(sut.Arrs).To(Equal([][2]float64{{1.5, 0}, {2, 3}}))
+		})
+
+		It("should zero array elements beyond the list", func() {
+			sut := lists{Arr: [3]int{7, 8, 9}}
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"arr": list(n("1")),
+			}, &sut)).To(Succeed())
+			Expect(sut.Arr).To(Equal([3]int{1, 0, 0}))
+		})
+
+		It("should report arrays too short for the list", func() {
+			var sut lists
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"arrs": list(list(n("1")), list(n("1"), n("2"), n("3"))),
+			}, &sut)
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalLengthError{}))
+			Expect(err.(*UnmarshalLengthError).Path).To(Equal("arrs[1]"))
+			Expect(err.(*UnmarshalLengthError).Len).To(Equal(3))
+			Expect(err.Error()).To(Equal("ddb: cannot unmarshal L of 3 elements into Go value of type [2]float64 at arrs[1]"))
+		})
+
+		It("should report mismatched elements with path in strict mode", func() {
+			var sut lists
+			d := map[string]*dynamodb.AttributeValue{
+				"ints": list(n("1"), str("x")),
+			}
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+			Expect(sut.Ints).To(Equal([]int{1, 0}))
+
+			err := Unmarshal(d, &sut, Strict())
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+			Expect(err.(*UnmarshalTypeError).Path).To(Equal("ints[1]"))
+		})
+
+		It("should report lists for other types in strict mode", func() {
+			var sut struct {
+				S string `dynamodb:"s"`
+			}
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{"s": list(str("a"))}, &sut, Strict())
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+		})
+	})
+
+	Context("NULL", func() {
+		type record struct {
+			Str     string            `dynamodb:"str"`
+			Int     int               `dynamodb:"int"`
+			Ptr     *string           `dynamodb:"ptr"`
+			Map     map[string]string `dynamodb:"map"`
+			Slice   []int             `dynamodb:"slice"`
+			Iface   interface{}       `dynamodb:"iface"`
+			Child   *child            `dynamodb:"child"`
+			Expires time.Time         `dynamodb:"expires,unixtime"`
+			Updated *time.Time        `dynamodb:"updated,unixmilli"`
+			Money   money             `dynamodb:"money"`
+		}
+
+		null := &dynamodb.AttributeValue{NULL: aws.Bool(true)}
+
+		var d map[string]*dynamodb.AttributeValue
+		var previous func() record
+
+		BeforeEach(func() {
+			d = map[string]*dynamodb.AttributeValue{
+				"str": null, "int": null, "ptr": null, "map": null, "slice": null,
+				"iface": null, "child": null, "expires": null, "updated": null,
+			}
+			previous = func() record {
+				now := time.Unix(1500000000, 0)
+				return record{
+					Str:     "foo",
+					Int:     1,
+					Ptr:     aws.String("bar"),
+					Map:     map[string]string{"a": "b"},
+					Slice:   []int{1},
+					Iface:   "baz",
+					Child:   &child{Content: "c"},
+					Expires: now,
+					Updated: &now,
+				}
+			}
+		})
+
+		It("should reset the destination to its zero value", func() {
+			sut := previous()
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+			Expect(sut).To(Equal(record{}))
+		})
+
+		It("should keep the destination with PreserveOnNull", func() {
+			sut := previous()
+			Expect(Unmarshal(d, &sut, PreserveOnNull())).To(Succeed())
+			Expect(sut).To(Equal(previous()))
+		})
+
+		It("should clear values marshaled as NULL", func() {
+			sut := previous()
+			Expect(Unmarshal(Marshal(&record{}), &sut)).To(Succeed())
+			Expect(sut.Str).To(BeEmpty())
+			Expect(sut.Ptr).To(BeNil())
+			Expect(sut.Map).To(BeNil())
+			Expect(sut.Slice).To(BeNil())
+			Expect(sut.Iface).To(BeNil())
+			Expect(sut.Child).To(BeNil())
+			Expect(sut.Updated).To(BeNil())
+		})
+
+		It("should reset nested values", func() {
+			sut := struct {
+				Child child     `dynamodb:"child"`
+				List  []*string `dynamodb:"list"`
+			}{Child: child{Content: "c"}}
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"child": &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{"content": null}},
+				"list":  &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{null}},
+			}, &sut)).To(Succeed())
+			Expect(sut.Child.Content).To(BeEmpty())
+			Expect(sut.List).To(Equal([]*string{nil}))
+		})
+
+		It("should pass NULL to an Unmarshaler", func() {
+			sut := record{Money: money{Cents: 100}}
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{"money": null}, &sut)
+			Expect(err).To(MatchError("money must be a number"))
+		})
+
+		It("should reset the destination of a single attribute value", func() {
+			sut := aws.String("foo")
+			Expect(UnmarshalAttributeValue(null, &sut)).To(Succeed())
+			Expect(sut).To(BeNil())
+		})
+	})
+
+	Context("Decoder", func() {
+		type doc struct {
+			Name  string      `dynamodbav:"n" json:"name"`
+			Count int         `json:"count"`
+			Any   interface{} `json:"any"`
+		}
+
+		d := map[string]*dynamodb.AttributeValue{
+			"n":     &dynamodb.AttributeValue{S: aws.String("foo")},
+			"count": &dynamodb.AttributeValue{S: aws.String("1")},
+			"any":   &dynamodb.AttributeValue{N: aws.String("1.5")},
+		}
+
+		It("should decode with its options", func() {
+			dec := NewDecoder(TagNames("dynamodbav", "json"), UseNumber())
+
+			var sut doc
+			Expect(dec.Decode(d, &sut)).To(Succeed())
+			Expect(sut).To(Equal(doc{Name: "foo", Any: json.Number("1.5")}))
+
+			var n interface{}
+			Expect(dec.DecodeAttributeValue(d["any"], &n)).To(Succeed())
+			Expect(n).To(Equal(json.Number("1.5")))
+		})
+
+		It("should report errors in strict mode", func() {
+			var sut doc
+			err := NewDecoder(TagNames("json"), Strict()).Decode(d, &sut)
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+			Expect(err.(*UnmarshalTypeError).Path).To(Equal("count"))
+		})
+
+		It("should decode like Unmarshal without options", func() {
+			var want, sut doc
+			Expect(Unmarshal(d, &want)).To(Succeed())
+			Expect(NewDecoder().Decode(d, &sut)).To(Succeed())
+			Expect(sut).To(Equal(want))
+		})
+
+		It("should reject non-pointer values", func() {
+			var sut doc
+			Expect(NewDecoder().Decode(d, sut)).NotTo(Succeed())
+			Expect(NewDecoder().DecodeAttributeValue(d["n"], sut)).NotTo(Succeed())
+		})
+
+		It("should reject nil pointers", func() {
+			Expect(NewDecoder().Decode(d, (*doc)(nil))).To(MatchError("value must be a non-nil pointer"))
+			Expect(NewDecoder().DecodeAttributeValue(d["n"], (*doc)(nil))).To(MatchError("value must be a non-nil pointer"))
+		})
+	})
+
+	Context("TypeDecoder", func() {
+		bigIntType := reflect.TypeOf((*big.Int)(nil))
+
+		decodeBigInt := TypeDecoder(bigIntType, func(value *dynamodb.AttributeValue, v reflect.Value) error {
+			if value.NULL != nil {
+				v.Set(reflect.Zero(bigIntType))
+				return nil
+			}
+			n, ok := new(big.Int).SetString(aws.StringValue(value.N), 10)
+			if !ok {
+				return errors.New("not an integer")
+			}
+			v.Set(reflect.ValueOf(n))
+			return nil
+		})
+
+		type account struct {
+			Balance *big.Int            `dynamodb:"balance"`
+			Debt    *big.Int            `dynamodb:"debt"`
+			History []*big.Int          `dynamodb:"history"`
+			Limits  map[string]*big.Int `dynamodb:"limits"`
+		}
+
+		It("should decode registered types by their function", func() {
+			sut := account{Debt: big.NewInt(1)}
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"balance": &dynamodb.AttributeValue{N: aws.String("123456789012345678901234567890")},
+				"debt":    &dynamodb.AttributeValue{NULL: aws.Bool(true)},
+				"history": &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+					&dynamodb.AttributeValue{N: aws.String("1")},
+				}},
+				"limits": &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+					"daily": &dynamodb.AttributeValue{N: aws.String("-5")},
+				}},
+			}, &sut, decodeBigInt)).To(Succeed())
+
+			Expect(sut.Balance.String()).To(Equal("123456789012345678901234567890"))
+			Expect(sut.Debt).To(BeNil())
+			Expect(sut.History).To(Equal([]*big.Int{big.NewInt(1)}))
+			Expect(sut.Limits).To(Equal(map[string]*big.Int{"daily": big.NewInt(-5)}))
+		})
+
+		It("should take precedence over Unmarshaler", func() {
+			var sut money
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{S: aws.String("free")}, &sut,
+				TypeDecoder(reflect.TypeOf(money{}), func(value *dynamodb.AttributeValue, v reflect.Value) error {
+					v.Set(reflect.ValueOf(money{Cents: 0}))
+					return nil
+				}))).To(Succeed())
+		})
+
+		It("should decode a registered item type", func() {
+			var sut account
+			Expect(NewDecoder(TypeDecoder(reflect.TypeOf(account{}), func(value *dynamodb.AttributeValue, v reflect.Value) error {
+				v.FieldByName("Balance").Set(reflect.ValueOf(big.NewInt(int64(len(value.M)))))
+				return nil
+			})).Decode(map[string]*dynamodb.AttributeValue{
+				"a": &dynamodb.AttributeValue{S: aws.String("x")},
+			}, &sut)).To(Succeed())
+			Expect(sut.Balance).To(Equal(big.NewInt(1)))
+		})
+
+		It("should take precedence over Unmarshaler for items", func() {
+			var sut money
+			Expect(NewDecoder(TypeDecoder(reflect.TypeOf(money{}), func(value *dynamodb.AttributeValue, v reflect.Value) error {
+				v.Set(reflect.ValueOf(money{Cents: int64(len(value.M))}))
+				return nil
+			})).Decode(map[string]*dynamodb.AttributeValue{
+				"a": &dynamodb.AttributeValue{S: aws.String("x")},
+			}, &sut)).To(Succeed())
+			Expect(sut.Cents).To(Equal(int64(1)))
+		})
+
+		It("should leave time format fields to their tag option", func() {
+			var sut struct {
+				Expires time.Time `dynamodb:"expires,unixtime"`
+			}
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"expires": &dynamodb.AttributeValue{N: aws.String("1500000000")},
+			}, &sut, TypeDecoder(reflect.TypeOf(time.Time{}), func(value *dynamodb.AttributeValue, v reflect.Value) error {
+				return errors.New("not called")
+			}))).To(Succeed())
+			Expect(sut.Expires.Equal(time.Unix(1500000000, 0))).To(BeTrue())
+		})
+
+		It("should return errors as they are", func() {
+			var sut account
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"balance": &dynamodb.AttributeValue{N: aws.String("1.5")},
+			}, &sut, decodeBigInt)
+			Expect(err).To(MatchError("not an integer"))
+		})
+	})
+
+	Context("strict mode", func() {
+		type order struct {
+			Price int `dynamodb:"price"`
+		}
+
+		type orders struct {
+			Name   string  `dynamodb:"name"`
+			Orders []order `dynamodb:"orders"`
+		}
+
+		var d map[string]*dynamodb.AttributeValue
+
+		BeforeEach(func() {
+			d = map[string]*dynamodb.AttributeValue{
+				"name": &dynamodb.AttributeValue{S: aws.String("foo")},
+				"orders": &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+					&dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+						"price": &dynamodb.AttributeValue{N: aws.String("100")},
+					}},
+					&dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+						"price": &dynamodb.AttributeValue{S: aws.String("200")},
+					}},
+				}},
+			}
+		})
+
+		It("should skip mismatched value by default", func() {
+			var sut orders
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+			Expect(sut.Name).To(Equal("foo"))
+			Expect(sut.Orders).Should(HaveLen(2))
+			Expect(sut.Orders[0].Price).To(Equal(100))
+			Expect(sut.Orders[1].Price).To(Equal(0))
+		})
+
+		It("should return UnmarshalTypeError with path", func() {
+			var sut orders
+			err := Unmarshal(d, &sut, Strict())
+			Expect(err).To(HaveOccurred())
+
+			e, ok := err.(*UnmarshalTypeError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("orders[1].price"))
+			Expect(e.Attribute).To(Equal("S"))
+			Expect(e.GoType.Kind()).To(Equal(reflect.Int))
+		})
+
+		It("should return UnmarshalTypeError for map element", func() {
+			var sut map[string]int
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"a": &dynamodb.AttributeValue{BOOL: aws.Bool(true)},
+			}, &sut, Strict())
+
+			e, ok := err.(*UnmarshalTypeError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("a"))
+			Expect(e.Attribute).To(Equal("BOOL"))
+		})
+
+		It("should skip M into scalar by default", func() {
+			sut := child{Content: "bar"}
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"content": &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{}},
+			}, &sut)).To(Succeed())
+			Expect(sut.Content).To(Equal("bar"))
+		})
+
+		It("should return UnmarshalTypeError for M into scalar", func() {
+			var sut child
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"content": &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{}},
+			}, &sut, Strict())
+
+			e, ok := err.(*UnmarshalTypeError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("content"))
+			Expect(e.Attribute).To(Equal("M"))
+		})
+
+		It("should return UnmarshalTypeError without path for top-level map", func() {
+			var sut map[int]string
+			d := map[string]*dynamodb.AttributeValue{
+				"1": &dynamodb.AttributeValue{S: aws.String("foo")},
+			}
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+			Expect(sut).To(BeNil())
+
+			err := Unmarshal(d, &sut, Strict())
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+			Expect(err.Error()).To(Equal("ddb: cannot unmarshal M into Go value of type map[int]string"))
+		})
+
+		It("should return UnmarshalTypeError for item into scalar", func() {
+			var sut int
+			d := map[string]*dynamodb.AttributeValue{
+				"n": &dynamodb.AttributeValue{N: aws.String("1")},
+			}
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+
+			err := Unmarshal(d, &sut, Strict())
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+			Expect(err.(*UnmarshalTypeError).Attribute).To(Equal("M"))
+			Expect(err.(*UnmarshalTypeError).GoType).To(Equal(reflect.TypeOf(0)))
+		})
+	})
+
+	Context("numeric attribute", func() {
+		unmarshalNumber := func(n string, opts ...DecodeOption) (sample, error) {
+			var sut sample
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"int":     &dynamodb.AttributeValue{N: aws.String(n)},
+				"int8":    &dynamodb.AttributeValue{N: aws.String(n)},
+				"uint":    &dynamodb.AttributeValue{N: aws.String(n)},
+				"float32": &dynamodb.AttributeValue{N: aws.String(n)},
+			}, &sut, opts...)
+			return sut, err
+		}
+
+		It("should accept integral numbers in any notation", func() {
+			sut, err := unmarshalNumber("1e2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut.Int).To(Equal(100))
+			Expect(sut.Int8).To(Equal(int8(100)))
+			Expect(sut.Uint).To(Equal(uint(100)))
+			Expect(sut.Float32).To(Equal(float32(100)))
+		})
+
+		It("should return UnmarshalNumberError on overflow", func() {
+			_, err := unmarshalNumber("300")
+			Expect(err).To(HaveOccurred())
+
+			e, ok := err.(*UnmarshalNumberError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("int8"))
+			Expect(e.Number).To(Equal("300"))
+			Expect(e.GoType.Kind()).To(Equal(reflect.Int8))
+			Expect(e.Err).To(Equal(strconv.ErrRange))
+		})
+
+		It("should check the range of numbers in any notation", func() {
+			var i int8
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{N: aws.String("-1.28e2")}, &i)).To(Succeed())
+			Expect(i).To(Equal(int8(-128)))
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{N: aws.String("1.28e2")}, &i)).To(BeAssignableToTypeOf(&UnmarshalNumberError{}))
+
+			var u uint8
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{N: aws.String("2.55e2")}, &u)).To(Succeed())
+			Expect(u).To(Equal(uint8(255)))
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{N: aws.String("2.56e2")}, &u)).To(BeAssignableToTypeOf(&UnmarshalNumberError{}))
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{N: aws.String("-1e0")}, &u)).To(BeAssignableToTypeOf(&UnmarshalNumberError{}))
+
+			var n int64
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{N: aws.String("-9.223372036854775808e18")}, &n)).To(Succeed())
+			Expect(n).To(Equal(int64(-1 << 63)))
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{N: aws.String("9.223372036854775808e18")}, &n)).To(BeAssignableToTypeOf(&UnmarshalNumberError{}))
+		})
+
+		It("should return UnmarshalNumberError on negative unsigned", func() {
+			var sut sample
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"uint": &dynamodb.AttributeValue{N: aws.String("-1")},
+			}, &sut)
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalNumberError{}))
+		})
+
+		It("should return UnmarshalNumberError on float overflow", func() {
+			var sut sample
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"float32": &dynamodb.AttributeValue{N: aws.String("1e39")},
+			}, &sut)
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalNumberError{}))
+		})
+
+		It("should return UnmarshalNumberError on non-integral number", func() {
+			var sut sample
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"int": &dynamodb.AttributeValue{N: aws.String("1.5")},
+			}, &sut)
+
+			e, ok := err.(*UnmarshalNumberError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("int"))
+		})
+
+		It("should return UnmarshalNumberError on malformed number", func() {
+			var sut sample
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"int": &dynamodb.AttributeValue{N: aws.String("abc")},
+			}, &sut)
+
+			e, ok := err.(*UnmarshalNumberError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Err).To(Equal(strconv.ErrSyntax))
+		})
+
+		It("should truncate non-integral number with TruncateFloats", func() {
+			var sut sample
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"int":  &dynamodb.AttributeValue{N: aws.String("-1.9")},
+				"uint": &dynamodb.AttributeValue{N: aws.String("2.5")},
+			}, &sut, TruncateFloats())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sut.Int).To(Equal(-1))
+			Expect(sut.Uint).To(Equal(uint(2)))
+		})
+
+		It("should not truncate out of range number with TruncateFloats", func() {
+			_, err := unmarshalNumber("300.5", TruncateFloats())
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalNumberError{}))
+		})
+	})
+
+	Context("omitempty field", func() {
+		type sparse struct {
+			GSI    string `dynamodb:"gsi,omitempty"`
+			Legacy string `dynamodb:"legacy,omitifempty"`
+		}
+
+		It("should be unmarshaled", func() {
+			var sut sparse
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"gsi":    &dynamodb.AttributeValue{S: aws.String("foo")},
+				"legacy": &dynamodb.AttributeValue{S: aws.String("bar")},
+			}, &sut)).To(Succeed())
+			Expect(sut.GSI).To(Equal("foo"))
+			Expect(sut.Legacy).To(Equal("bar"))
+		})
+	})
+
+	Context("dynamodb tag", func() {
+		type entity struct {
+			ID      string `dynamodb:"pk" json:"id"`
+			Name    string `json:"name"`
+			Version int    `dynamodbav:"ver" json:"version"`
+		}
+
+		d := map[string]*dynamodb.AttributeValue{
+			"pk":      &dynamodb.AttributeValue{S: aws.String("1")},
+			"id":      &dynamodb.AttributeValue{S: aws.String("2")},
+			"name":    &dynamodb.AttributeValue{S: aws.String("foo")},
+			"Name":    &dynamodb.AttributeValue{S: aws.String("bar")},
+			"version": &dynamodb.AttributeValue{N: aws.String("3")},
+		}
+
+		It("should read only dynamodb tag by default", func() {
+			var sut entity
+			Expect(Unmarshal(d, &sut)).To(Succeed())
+			Expect(sut).To(Equal(entity{ID: "1", Name: "bar"}))
+		})
+
+		It("should fall back to json tag when configured", func() {
+			var sut entity
+			Expect(Unmarshal(d, &sut, TagNames("dynamodb", "json"))).To(Succeed())
+			Expect(sut).To(Equal(entity{ID: "1", Name: "foo", Version: 3}))
+		})
+
+		It("should use only configured tag names", func() {
+			var sut entity
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"pk":   &dynamodb.AttributeValue{S: aws.String("1")},
+				"name": &dynamodb.AttributeValue{S: aws.String("foo")},
+				"Name": &dynamodb.AttributeValue{S: aws.String("bar")},
+				"ver":  &dynamodb.AttributeValue{N: aws.String("3")},
+			}, &sut, TagNames("dynamodb", "dynamodbav"))).To(Succeed())
+			Expect(sut).To(Equal(entity{ID: "1", Name: "bar", Version: 3}))
+		})
+	})
+
+	Context("set into map", func() {
+		type sets struct {
+			Members map[string]struct{} `dynamodb:"members"`
+			Numbers map[int]bool        `dynamodb:"numbers"`
+		}
+
+		It("should be set members to map keys", func() {
+			var sut sets
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"members": &dynamodb.AttributeValue{SS: []*string{aws.String("a"), aws.String("b")}},
+				"numbers": &dynamodb.AttributeValue{NS: []*string{aws.String("1"), aws.String("3")}},
+			}, &sut)).To(Succeed())
+			Expect(sut.Members).To(Equal(map[string]struct{}{"a": struct{}{}, "b": struct{}{}}))
+			Expect(sut.Numbers).To(Equal(map[int]bool{1: true, 3: true}))
+		})
+	})
+
+	Context("number set", func() {
+		type numbers struct {
+			Ints     []int      `dynamodb:"ints"`
+			Int64s   []int64    `dynamodb:"int64s"`
+			Uint32s  []uint32   `dynamodb:"uint32s"`
+			Floats   []float64  `dynamodb:"floats"`
+			Ptrs     []*float32 `dynamodb:"ptrs"`
+			Decimals []string   `dynamodb:"decimals"`
+			Bools    []bool     `dynamodb:"bools"`
+		}
+
+		ns := func(n ...string) *dynamodb.AttributeValue {
+			return &dynamodb.AttributeValue{NS: aws.StringSlice(n)}
+		}
+
+		It("should be decoded into element type of slice", func() {
+			var sut numbers
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"ints":     ns("1", "-2"),
+				"int64s":   ns("1112222111222111"),
+				"uint32s":  ns("4294967295"),
+				"floats":   ns("1.5", "2"),
+				"ptrs":     ns("0.25"),
+				"decimals": ns("0.10", "1e3"),
+			}, &sut)).To(Succeed())
+
+			Expect(sut.Ints).To(Equal([]int{1, -2}))
+			Expect(sut.Int64s).To(Equal([]int64{1112222111222111}))
+			Expect(sut.Uint32s).To(Equal([]uint32{4294967295}))
+			Expect(sut.Floats).To(Equal([]float64{1.5, 2}))
+			Expect(sut.Ptrs).Should(HaveLen(1))
+			Expect(*sut.Ptrs[0]).To(Equal(float32(0.25)))
+			Expect(sut.Decimals).To(Equal([]string{"0.10", "1e3"}))
+		})
+
+		It("should return UnmarshalNumberError for unrepresentable member", func() {
+			var sut numbers
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"uint32s": ns("1", "-1"),
+			}, &sut)
+
+			e, ok := err.(*UnmarshalNumberError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("uint32s[1]"))
+		})
+
+		It("should return UnmarshalTypeError for non-numeric slice in strict mode", func() {
+			var sut numbers
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"bools": ns("1"),
+			}, &sut, Strict())
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+		})
+	})
+
+	Context("Unmarshaler", func() {
+		It("should be used at every nesting level", func() {
+			var sut product
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"price": &dynamodb.AttributeValue{N: aws.String("12.34")},
+				"sale":  &dynamodb.AttributeValue{N: aws.String("10.00")},
+				"history": &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+					&dynamodb.AttributeValue{N: aws.String("1.00")},
+					&dynamodb.AttributeValue{N: aws.String("2.05")},
+				}},
+				"prices": &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+					"jpy": &dynamodb.AttributeValue{N: aws.String("0.05")},
+				}},
+				"color": &dynamodb.AttributeValue{S: aws.String("blue")},
+			}, &sut)).To(Succeed())
+
+			Expect(sut.Price).To(Equal(money{1234}))
+			Expect(sut.Sale).To(Equal(&money{1000}))
+			Expect(sut.History).To(Equal([]money{{100}, {205}}))
+			Expect(sut.Prices).To(Equal(map[string]money{"jpy": {5}}))
+			Expect(sut.Color).To(Equal(blue))
+		})
+
+		It("should return error of Unmarshaler", func() {
+			var sut product
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"color": &dynamodb.AttributeValue{S: aws.String("green")},
+			}, &sut)
+			Expect(err).To(MatchError("unknown color"))
+		})
+	})
+
+	Context("encoding.TextUnmarshaler and json.Unmarshaler", func() {
+		var d map[string]*dynamodb.AttributeValue
+
+		BeforeEach(func() {
+			loc := &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+				"coords": &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+					&dynamodb.AttributeValue{N: aws.String("1")},
+					&dynamodb.AttributeValue{N: aws.String("2")},
+				}},
+			}}
+			d = map[string]*dynamodb.AttributeValue{
+				"ip":    &dynamodb.AttributeValue{S: aws.String("192.0.2.1")},
+				"loc":   loc,
+				"route": &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{loc}},
+			}
+		})
+
+		It("should restore TextUnmarshaler from string", func() {
+			var sut device
+			Expect(Unmarshal(d, &sut, UseTextMarshaler())).To(Succeed())
+			Expect(sut.IP.String()).To(Equal("192.0.2.1"))
+		})
+
+		It("should restore json.Unmarshaler from document", func() {
+			var sut device
+			Expect(Unmarshal(d, &sut, UseJSONMarshaler())).To(Succeed())
+			Expect(sut.Loc).To(Equal(point{1, 2}))
+			Expect(sut.Route).To(Equal([]point{{1, 2}}))
+		})
+
+		It("should round trip", func() {
+			s := &device{IP: net.ParseIP("2001:db8::1"), Loc: point{5, 6}}
+			var sut device
+			Expect(Unmarshal(Marshal(s, UseTextMarshaler(), UseJSONMarshaler()), &sut, UseTextMarshaler(), UseJSONMarshaler())).To(Succeed())
+			Expect(sut.IP.Equal(s.IP)).To(BeTrue())
+			Expect(sut.Loc).To(Equal(s.Loc))
+		})
+	})
+
+	Context("time", func() {
+		type event struct {
+			At       time.Time     `dynamodb:"at"`
+			Expires  time.Time     `dynamodb:"expires,unixtime"`
+			Created  *time.Time    `dynamodb:"created,unixmilli"`
+			Interval time.Duration `dynamodb:"interval"`
+			Timeout  time.Duration `dynamodb:"timeout"`
+		}
+
+		at := time.Date(2020, 5, 18, 12, 34, 56, 789000000, time.UTC)
+
+		It("should be restored from attribute values", func() {
+			var sut event
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"at":       &dynamodb.AttributeValue{S: aws.String("2020-05-18T12:34:56.789Z")},
+				"expires":  &dynamodb.AttributeValue{N: aws.String("1589805296")},
+				"created":  &dynamodb.AttributeValue{N: aws.String("1589805296789")},
+				"interval": &dynamodb.AttributeValue{N: aws.String("90000000000")},
+				"timeout":  &dynamodb.AttributeValue{S: aws.String("1m30s")},
+			}, &sut)).To(Succeed())
+
+			Expect(sut.At.Equal(at)).To(BeTrue())
+			Expect(sut.Expires.Equal(at.Truncate(time.Second))).To(BeTrue())
+			Expect(sut.Created).NotTo(BeNil())
+			Expect(sut.Created.Equal(at)).To(BeTrue())
+			Expect(sut.Interval).To(Equal(90 * time.Second))
+			Expect(sut.Timeout).To(Equal(90 * time.Second))
+		})
+
+		It("should round trip", func() {
+			var sut event
+			s := &event{At: at, Expires: at, Created: &at, Interval: time.Hour}
+			Expect(Unmarshal(Marshal(s), &sut)).To(Succeed())
+			Expect(sut.At.Equal(at)).To(BeTrue())
+			Expect(sut.Created.Equal(at)).To(BeTrue())
+			Expect(sut.Interval).To(Equal(time.Hour))
+		})
+
+		It("should return UnmarshalTimeError for malformed time", func() {
+			var sut event
+			err := Unmarshal(map[string]*dynamodb.AttributeValue{
+				"at": &dynamodb.AttributeValue{S: aws.String("yesterday")},
+			}, &sut)
+
+			e, ok := err.(*UnmarshalTimeError)
+			Expect(ok).To(BeTrue())
+			Expect(e.Path).To(Equal("at"))
+		})
+	})
+
+	Context("embedded struct", func() {
+		It("should populate fields of embedded struct", func() {
+			var sut user
+			Expect(Unmarshal(map[string]*dynamodb.AttributeValue{
+				"pk":         &dynamodb.AttributeValue{S: aws.String("USER#1")},
+				"sk":         &dynamodb.AttributeValue{S: aws.String("PROFILE")},
+				"created_at": &dynamodb.AttributeValue{S: aws.String("2020-01-02T03:04:05Z")},
+				"by":         &dynamodb.AttributeValue{S: aws.String("admin")},
+				"note":       &dynamodb.AttributeValue{S: aws.String("note")},
+				"name":       &dynamodb.AttributeValue{S: aws.String("foo")},
+			}, &sut)).To(Succeed())
+
+			Expect(sut.PK).To(Equal("USER#1"))
+			Expect(sut.SK).To(Equal("PROFILE"))
+			Expect(sut.BaseEntity.SK).To(BeEmpty())
+			Expect(sut.CreatedAt.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))).To(BeTrue())
+			Expect(sut.Audit).NotTo(BeNil())
+			Expect(sut.Audit.By).To(Equal("admin"))
+			Expect(sut.Audit.Note).To(BeEmpty())
+			Expect(sut.Labels.Note).To(BeEmpty())
+			Expect(sut.Name).To(Equal("foo"))
+		})
+	})
+
+	Context("UnmarshalAttributeValue", func() {
+		It("should unmarshal a single value", func() {
+			var sut []int
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{NS: []*string{aws.String("1"), aws.String("2")}}, &sut)).To(Succeed())
+			Expect(sut).To(Equal([]int{1, 2}))
+		})
+
+		It("should use Unmarshaler", func() {
+			var sut money
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{N: aws.String("19.99")}, &sut)).To(Succeed())
+			Expect(sut.Cents).To(Equal(int64(1999)))
+		})
+
+		It("should report number out of range", func() {
+			var sut int8
+			err := UnmarshalAttributeValue(&dynamodb.AttributeValue{N: aws.String("300")}, &sut)
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalNumberError{}))
+			Expect(err.Error()).NotTo(ContainSubstring(" at "))
+		})
+
+		It("should require a pointer", func() {
+			var sut int
+			Expect(UnmarshalAttributeValue(&dynamodb.AttributeValue{N: aws.String("1")}, sut)).NotTo(Succeed())
+		})
+	})
 })